@@ -0,0 +1,140 @@
+package strparam
+
+import "strings"
+
+// MatchPrefix returns list params if the beginning of input string matched to schema.
+// Also returns the unconsumed remainder of input string.
+//
+// Parameters are lazy (as in Lookup), trailing parameter captures the tail (remainder is empty).
+func (s *Pattern) MatchPrefix(in string) (bool, Params, string) {
+	tokens := s.matchableTokens()
+	if tokens == nil {
+		return false, nil, ""
+	}
+
+	var loc []int
+	var end int
+
+	if tokens[0].Mode == PARAMETER && len(tokens) > 1 && tokens[1].Mode != END {
+		// leading parameter captures up to the next constant
+		found := strings.Index(in, tokens[1].Raw)
+		if found < 0 || !tokens[0].acceptsValue(in[:found]) {
+			return false, nil, ""
+		}
+		var ok bool
+		loc, end, ok = matchTokens(tokens[1:], in, found, []int{0, found})
+		if !ok {
+			return false, nil, ""
+		}
+	} else {
+		var ok bool
+		loc, end, ok = matchTokens(tokens, in, 0, nil)
+		if !ok {
+			return false, nil, ""
+		}
+	}
+
+	return true, s.paramsByLoc(in, loc), in[end:]
+}
+
+// MatchSuffix returns list params if the ending of input string matched to schema.
+// Also returns the unconsumed beginning of input string.
+//
+// Candidates are tried by position of start from left to right, each candidate is matched
+// with lazy parameters (as in Lookup) and is not retried with longer values of parameters.
+// The first candidate whose match ends at the end of input string wins, so a candidate
+// matching only with longer values of parameters is skipped in favor of the next one
+// (eg "a{x}c" on "a1ca2c" returns x=2, not x=1ca2).
+// Leading parameter captures from begin of input string, so the input string is the only candidate.
+func (s *Pattern) MatchSuffix(in string) (bool, Params, string) {
+	tokens := s.matchableTokens()
+	if tokens == nil {
+		return false, nil, ""
+	}
+
+	var prevEnd int
+	for {
+		loc := searchTokens(tokens, in, prevEnd, 2+2*s.NumParams)
+		if loc == nil {
+			return false, nil, ""
+		}
+		if loc[1] == len(in) {
+			return true, s.paramsByLoc(in, loc[2:]), in[:loc[0]]
+		}
+		if tokens[0].Mode == PARAMETER {
+			// leading parameter always starts at begin of input string
+			return false, nil, ""
+		}
+		// next candidate after the start of current
+		prevEnd = loc[0] + 1
+	}
+}
+
+// Contains returns true if input string contains the pattern.
+//
+// See FindAllIndex for details of the search.
+func (s *Pattern) Contains(in string) bool {
+	return len(s.FindAllIndex(in, 1)) > 0
+}
+
+// matchableTokens returns tokens without START or nil for empty pattern.
+func (s *Pattern) matchableTokens() Tokens {
+	if s == nil {
+		return nil
+	}
+	tokens := s.Tokens
+	if len(tokens) > 0 && tokens[0].Mode == START {
+		tokens = tokens[1:]
+	}
+	if len(tokens) == 0 || tokens[0].Mode == END {
+		// nothing not matches to anything
+		return nil
+	}
+	return tokens
+}
+
+// paramsByLoc returns list params by offsets of parameters (pairs of start and end).
+func (s *Pattern) paramsByLoc(in string, loc []int) Params {
+	names := s.paramNames()
+	params := make(Params, 0, len(names))
+	for i, name := range names {
+		if 2*i+1 >= len(loc) {
+			break
+		}
+		params = append(params, Param{
+			Name:  name,
+			Value: in[loc[2*i]:loc[2*i+1]],
+		})
+	}
+	return params
+}
+
+// FindPrefix returns full pattern matched for the beginning of incoming string
+// and the unconsumed remainder of incoming string.
+//
+// Unlike Find the branches of tree are visited in sorted order until the first complete pattern.
+// Parameters are lazy, trailing parameter captures the tail.
+// Can be used to dispatch by the first part of string and hand the rest to a other storage.
+func (r *Store) FindPrefix(in string) (*Pattern, string) {
+	for _, start := range r.load().root.Childs {
+		if start.Token.Mode != START {
+			continue
+		}
+		for _, child := range start.Childs {
+			tokens := Tokens{StartToken}
+			end, ok := walkBranch(in, 0, child, &tokens, false)
+			if !ok {
+				continue
+			}
+
+			numParams := 0
+			for _, t := range tokens {
+				if t.Mode == PARAMETER_PARSED {
+					numParams++
+				}
+			}
+			return &Pattern{Tokens: tokens, NumParams: numParams}, in[end:]
+		}
+	}
+	return nil, ""
+}
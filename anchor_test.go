@@ -0,0 +1,111 @@
+package strparam
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPattern_MatchPrefix(t *testing.T) {
+	tests := []struct {
+		pattern  string
+		in       string
+		found    bool
+		want     Params
+		wantRest string
+	}{
+		{"foo", "", false, nil, ""},
+		{"foo", "foo", true, Params{}, ""},
+		{"foo", "foobar", true, Params{}, "bar"},
+		{"foo", "1foo", false, nil, ""},
+		{"/api/{version}/", "/api/v1/users/1", true, Params{{"version", "v1"}}, "users/1"},
+		{"/api/{version}/", "/api/v1", false, nil, ""},
+		{"{p1}qw{p2}", "123qw456", true, Params{{"p1", "123"}, {"p2", "456"}}, ""},
+		{"{p1}qw", "123qw456", true, Params{{"p1", "123"}}, "456"},
+		{"{p1}", "123", true, Params{{"p1", "123"}}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%q->%q", tt.pattern, tt.in), func(t *testing.T) {
+			p, err := Parse(tt.pattern)
+			require.NoError(t, err)
+			found, params, rest := p.MatchPrefix(tt.in)
+			assert.Equal(t, tt.found, found)
+			assert.EqualValues(t, tt.want, params)
+			assert.Equal(t, tt.wantRest, rest)
+		})
+	}
+}
+
+func TestPattern_MatchSuffix(t *testing.T) {
+	tests := []struct {
+		pattern  string
+		in       string
+		found    bool
+		want     Params
+		wantRest string
+	}{
+		{"foo", "", false, nil, ""},
+		{"foo", "foo", true, Params{}, ""},
+		{"foo", "barfoo", true, Params{}, "bar"},
+		{"foo", "foo1", false, nil, ""},
+		{"foo", "foofoo", true, Params{}, "foo"},
+		{".{ext}", "archive.tar.gz", true, Params{{"ext", "tar.gz"}}, "archive"},
+		{"/{id}/", "/a/b/", true, Params{{"id", "b"}}, "/a"},
+		{"{name}.log", "dir/app.log", true, Params{{"name", "dir/app"}}, ""},
+		{"{name}.log", "dir/app.log.1", false, nil, ""},
+		// overlapping candidates: the first one ends before the end with lazy parameter
+		{"a{x}c", "xxa1ca2c", true, Params{{"x", "2"}}, "xxa1c"},
+		// parameter is not retried with longer value ("2c")
+		{"a{x}c", "a1ca2cc", false, nil, ""},
+		{"{x}c", "a1ca2c", false, nil, ""},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%q->%q", tt.pattern, tt.in), func(t *testing.T) {
+			p, err := Parse(tt.pattern)
+			require.NoError(t, err)
+			found, params, rest := p.MatchSuffix(tt.in)
+			assert.Equal(t, tt.found, found)
+			assert.EqualValues(t, tt.want, params)
+			assert.Equal(t, tt.wantRest, rest)
+		})
+	}
+}
+
+func TestPattern_Contains(t *testing.T) {
+	p, err := Parse("id={id};")
+	require.NoError(t, err)
+	assert.True(t, p.Contains("foo id=1; bar"))
+	assert.False(t, p.Contains("foo id=1 bar"))
+	assert.False(t, (*Pattern)(nil).Contains("foo"))
+}
+
+func TestStore_FindPrefix(t *testing.T) {
+	root := NewStore()
+	root.AddNamed("api", "/api/{version}/")
+	root.AddNamed("static", "/static/")
+
+	sub := NewStore()
+	sub.AddNamed("user", "users/{id}")
+	sub.AddNamed("users", "users")
+
+	pattern, rest := root.FindPrefix("/api/v1/users/42")
+	require.NotNil(t, pattern)
+	assert.Equal(t, "api", pattern.Name())
+	assert.Equal(t, "users/42", rest)
+	assert.EqualValues(t, Tokens{StartToken, ConstToken("/api/"), ParsedParameterToken("version", "v1"), ConstToken("/"), NamedEndToken("api")}, pattern.Tokens)
+
+	subPattern := sub.Find(rest)
+	require.NotNil(t, subPattern)
+	assert.Equal(t, "user", subPattern.Name())
+
+	pattern, rest = root.FindPrefix("/static/app.js")
+	require.NotNil(t, pattern)
+	assert.Equal(t, "static", pattern.Name())
+	assert.Equal(t, "app.js", rest)
+
+	pattern, rest = root.FindPrefix("/other")
+	assert.Nil(t, pattern)
+	assert.Equal(t, "", rest)
+}
@@ -0,0 +1,4 @@
+// Package example is an example of code generated by strparam-gen.
+package example
+
+//go:generate go run github.com/gebv/strparam/cmd/strparam-gen -i patterns.txt -o patterns_gen.go -samples samples.txt
@@ -0,0 +1,560 @@
+// Code generated by strparam-gen. DO NOT EDIT.
+
+package example
+
+import (
+	"strings"
+)
+
+// Names of patterns.
+const (
+	PatternIndex     = "index"
+	PatternUserPage  = "user_page"
+	PatternUserPosts = "user_posts"
+	PatternUsers     = "users"
+	PatternStatic    = "static"
+	PatternLogin     = "login"
+	PatternLogout    = "logout"
+	PatternOrder     = "order"
+	PatternAny       = "any"
+	PatternBa        = "ba"
+	PatternB         = "b"
+)
+
+// IndexParams parameters of pattern "index": /
+type IndexParams struct {
+}
+
+// MatchIndex returns parameters if the input string matched to pattern "index" (same as strparam.Pattern.Lookup).
+func MatchIndex(in string) (res IndexParams, ok bool) {
+	offset := 0
+	if !strings.HasPrefix(in[offset:], "/") {
+		return res, false
+	}
+	offset += 1
+	return res, offset == len(in)
+}
+
+// UserPageParams parameters of pattern "user_page": /users/{id}
+type UserPageParams struct {
+	Id string // id
+}
+
+// MatchUserPage returns parameters if the input string matched to pattern "user_page" (same as strparam.Pattern.Lookup).
+func MatchUserPage(in string) (res UserPageParams, ok bool) {
+	offset := 0
+	if !strings.HasPrefix(in[offset:], "/users/") {
+		return res, false
+	}
+	offset += 7
+	res.Id = in[offset:]
+	offset = len(in)
+	return res, offset == len(in)
+}
+
+// UserPostsParams parameters of pattern "user_posts": /users/{id}/posts/{post}
+type UserPostsParams struct {
+	Id   string // id
+	Post string // post
+}
+
+// MatchUserPosts returns parameters if the input string matched to pattern "user_posts" (same as strparam.Pattern.Lookup).
+func MatchUserPosts(in string) (res UserPostsParams, ok bool) {
+	offset := 0
+	var i int
+	if !strings.HasPrefix(in[offset:], "/users/") {
+		return res, false
+	}
+	offset += 7
+	i = strings.Index(in[offset:], "/posts/")
+	if i < 0 {
+		return res, false
+	}
+	res.Id = in[offset : offset+i]
+	offset += i
+	if !strings.HasPrefix(in[offset:], "/posts/") {
+		return res, false
+	}
+	offset += 7
+	res.Post = in[offset:]
+	offset = len(in)
+	return res, offset == len(in)
+}
+
+// UsersParams parameters of pattern "users": /users/
+type UsersParams struct {
+}
+
+// MatchUsers returns parameters if the input string matched to pattern "users" (same as strparam.Pattern.Lookup).
+func MatchUsers(in string) (res UsersParams, ok bool) {
+	offset := 0
+	if !strings.HasPrefix(in[offset:], "/users/") {
+		return res, false
+	}
+	offset += 7
+	return res, offset == len(in)
+}
+
+// StaticParams parameters of pattern "static": /static/{path}
+type StaticParams struct {
+	Path string // path
+}
+
+// MatchStatic returns parameters if the input string matched to pattern "static" (same as strparam.Pattern.Lookup).
+func MatchStatic(in string) (res StaticParams, ok bool) {
+	offset := 0
+	if !strings.HasPrefix(in[offset:], "/static/") {
+		return res, false
+	}
+	offset += 8
+	res.Path = in[offset:]
+	offset = len(in)
+	return res, offset == len(in)
+}
+
+// LoginParams parameters of pattern "login": user {name} logged in from {ip} port {port}
+type LoginParams struct {
+	Name string // name
+	Ip   string // ip
+	Port string // port
+}
+
+// MatchLogin returns parameters if the input string matched to pattern "login" (same as strparam.Pattern.Lookup).
+func MatchLogin(in string) (res LoginParams, ok bool) {
+	offset := 0
+	var i int
+	if !strings.HasPrefix(in[offset:], "user ") {
+		return res, false
+	}
+	offset += 5
+	i = strings.Index(in[offset:], " logged in from ")
+	if i < 0 {
+		return res, false
+	}
+	res.Name = in[offset : offset+i]
+	offset += i
+	if !strings.HasPrefix(in[offset:], " logged in from ") {
+		return res, false
+	}
+	offset += 16
+	i = strings.Index(in[offset:], " port ")
+	if i < 0 {
+		return res, false
+	}
+	res.Ip = in[offset : offset+i]
+	offset += i
+	if !strings.HasPrefix(in[offset:], " port ") {
+		return res, false
+	}
+	offset += 6
+	res.Port = in[offset:]
+	offset = len(in)
+	return res, offset == len(in)
+}
+
+// LogoutParams parameters of pattern "logout": user {name} logged out
+type LogoutParams struct {
+	Name string // name
+}
+
+// MatchLogout returns parameters if the input string matched to pattern "logout" (same as strparam.Pattern.Lookup).
+func MatchLogout(in string) (res LogoutParams, ok bool) {
+	offset := 0
+	var i int
+	if !strings.HasPrefix(in[offset:], "user ") {
+		return res, false
+	}
+	offset += 5
+	i = strings.Index(in[offset:], " logged out")
+	if i < 0 {
+		return res, false
+	}
+	res.Name = in[offset : offset+i]
+	offset += i
+	if !strings.HasPrefix(in[offset:], " logged out") {
+		return res, false
+	}
+	offset += 11
+	return res, offset == len(in)
+}
+
+// OrderParams parameters of pattern "order": order={id}, amount={amount}
+type OrderParams struct {
+	Id     string // id
+	Amount string // amount
+}
+
+// MatchOrder returns parameters if the input string matched to pattern "order" (same as strparam.Pattern.Lookup).
+func MatchOrder(in string) (res OrderParams, ok bool) {
+	offset := 0
+	var i int
+	if !strings.HasPrefix(in[offset:], "order=") {
+		return res, false
+	}
+	offset += 6
+	i = strings.Index(in[offset:], ", amount=")
+	if i < 0 {
+		return res, false
+	}
+	res.Id = in[offset : offset+i]
+	offset += i
+	if !strings.HasPrefix(in[offset:], ", amount=") {
+		return res, false
+	}
+	offset += 9
+	res.Amount = in[offset:]
+	offset = len(in)
+	return res, offset == len(in)
+}
+
+// AnyParams parameters of pattern "any": /{path}
+type AnyParams struct {
+	Path string // path
+}
+
+// MatchAny returns parameters if the input string matched to pattern "any" (same as strparam.Pattern.Lookup).
+func MatchAny(in string) (res AnyParams, ok bool) {
+	offset := 0
+	if !strings.HasPrefix(in[offset:], "/") {
+		return res, false
+	}
+	offset += 1
+	res.Path = in[offset:]
+	offset = len(in)
+	return res, offset == len(in)
+}
+
+// BaParams parameters of pattern "ba": /ba{rest}
+type BaParams struct {
+	Rest string // rest
+}
+
+// MatchBa returns parameters if the input string matched to pattern "ba" (same as strparam.Pattern.Lookup).
+func MatchBa(in string) (res BaParams, ok bool) {
+	offset := 0
+	if !strings.HasPrefix(in[offset:], "/ba") {
+		return res, false
+	}
+	offset += 3
+	res.Rest = in[offset:]
+	offset = len(in)
+	return res, offset == len(in)
+}
+
+// BParams parameters of pattern "b": /b
+type BParams struct {
+}
+
+// MatchB returns parameters if the input string matched to pattern "b" (same as strparam.Pattern.Lookup).
+func MatchB(in string) (res BParams, ok bool) {
+	offset := 0
+	if !strings.HasPrefix(in[offset:], "/b") {
+		return res, false
+	}
+	offset += 2
+	return res, offset == len(in)
+}
+
+// Result of Match.
+type Result struct {
+	// Name of matched pattern.
+	Name   string
+	values [3]string
+	num    int
+}
+
+// Index returns parameters of pattern "index" if it is matched.
+func (r *Result) Index() (res IndexParams, ok bool) {
+	if r.Name != PatternIndex {
+		return res, false
+	}
+	return res, true
+}
+
+// UserPage returns parameters of pattern "user_page" if it is matched.
+func (r *Result) UserPage() (res UserPageParams, ok bool) {
+	if r.Name != PatternUserPage {
+		return res, false
+	}
+	res.Id = r.values[0]
+	return res, true
+}
+
+// UserPosts returns parameters of pattern "user_posts" if it is matched.
+func (r *Result) UserPosts() (res UserPostsParams, ok bool) {
+	if r.Name != PatternUserPosts {
+		return res, false
+	}
+	res.Id = r.values[0]
+	res.Post = r.values[1]
+	return res, true
+}
+
+// Users returns parameters of pattern "users" if it is matched.
+func (r *Result) Users() (res UsersParams, ok bool) {
+	if r.Name != PatternUsers {
+		return res, false
+	}
+	return res, true
+}
+
+// Static returns parameters of pattern "static" if it is matched.
+func (r *Result) Static() (res StaticParams, ok bool) {
+	if r.Name != PatternStatic {
+		return res, false
+	}
+	res.Path = r.values[0]
+	return res, true
+}
+
+// Login returns parameters of pattern "login" if it is matched.
+func (r *Result) Login() (res LoginParams, ok bool) {
+	if r.Name != PatternLogin {
+		return res, false
+	}
+	res.Name = r.values[0]
+	res.Ip = r.values[1]
+	res.Port = r.values[2]
+	return res, true
+}
+
+// Logout returns parameters of pattern "logout" if it is matched.
+func (r *Result) Logout() (res LogoutParams, ok bool) {
+	if r.Name != PatternLogout {
+		return res, false
+	}
+	res.Name = r.values[0]
+	return res, true
+}
+
+// Order returns parameters of pattern "order" if it is matched.
+func (r *Result) Order() (res OrderParams, ok bool) {
+	if r.Name != PatternOrder {
+		return res, false
+	}
+	res.Id = r.values[0]
+	res.Amount = r.values[1]
+	return res, true
+}
+
+// Any returns parameters of pattern "any" if it is matched.
+func (r *Result) Any() (res AnyParams, ok bool) {
+	if r.Name != PatternAny {
+		return res, false
+	}
+	res.Path = r.values[0]
+	return res, true
+}
+
+// Ba returns parameters of pattern "ba" if it is matched.
+func (r *Result) Ba() (res BaParams, ok bool) {
+	if r.Name != PatternBa {
+		return res, false
+	}
+	res.Rest = r.values[0]
+	return res, true
+}
+
+// B returns parameters of pattern "b" if it is matched.
+func (r *Result) B() (res BParams, ok bool) {
+	if r.Name != PatternB {
+		return res, false
+	}
+	return res, true
+}
+
+// Match returns the matched pattern and its parameters (same as strparam.Store.Find and then strparam.Pattern.Lookup).
+func Match(in string) (res Result, ok bool) {
+	if !findNode0(in, 0, &res) {
+		return Result{}, false
+	}
+	return res, true
+}
+
+// root
+func findNode0(in string, offset int, res *Result) bool {
+	return findNode1(in, offset, res)
+}
+
+// START
+func findNode1(in string, offset int, res *Result) bool {
+	// Const("/static/", len=8)
+	if offset+8 <= len(in) && in[offset:offset+8] == "/static/" {
+		return findNode2(in, offset+8, res)
+	}
+	// Const("/users/", len=7)
+	if offset+7 <= len(in) && in[offset:offset+7] == "/users/" {
+		return findNode5(in, offset+7, res)
+	}
+	// Const("order=", len=6)
+	if offset+6 <= len(in) && in[offset:offset+6] == "order=" {
+		return findNode12(in, offset+6, res)
+	}
+	// Const("user ", len=5)
+	if offset+5 <= len(in) && in[offset:offset+5] == "user " {
+		return findNode17(in, offset+5, res)
+	}
+	// Const("/ba", len=3)
+	if offset+3 <= len(in) && in[offset:offset+3] == "/ba" {
+		return findNode26(in, offset+3, res)
+	}
+	// Const("/b", len=2)
+	if offset+2 == len(in) && in[offset:offset+2] == "/b" {
+		if offset+2 == len(in) {
+			return findNode29(in, offset+2, res)
+		}
+	}
+	// Const("/", len=1)
+	if offset+1 <= len(in) && in[offset:offset+1] == "/" {
+		return findNode31(in, offset+1, res)
+	}
+	return false
+}
+
+// Const("/static/", len=8)
+func findNode2(in string, offset int, res *Result) bool {
+	// Param("{path}")
+	{
+		res.values[res.num] = in[offset:]
+		res.num++
+		res.Name = "static"
+		return true
+	}
+}
+
+// Const("/users/", len=7)
+func findNode5(in string, offset int, res *Result) bool {
+	// END("users")
+	if len(in) == offset {
+		res.Name = "users"
+		return true
+	}
+	// Param("{id}")
+	if i := strings.Index(in[offset:], "/posts/"); i >= 0 {
+		res.values[res.num] = in[offset : offset+i]
+		res.num++
+		return findNode8(in, offset+i+7, res)
+	} else {
+		res.values[res.num] = in[offset:]
+		res.num++
+		res.Name = "user_page"
+		return true
+	}
+}
+
+// Const("/posts/", len=7)
+func findNode8(in string, offset int, res *Result) bool {
+	// Param("{post}")
+	{
+		res.values[res.num] = in[offset:]
+		res.num++
+		res.Name = "user_posts"
+		return true
+	}
+}
+
+// Const("order=", len=6)
+func findNode12(in string, offset int, res *Result) bool {
+	// Param("{id}")
+	if i := strings.Index(in[offset:], ", amount="); i >= 0 {
+		res.values[res.num] = in[offset : offset+i]
+		res.num++
+		return findNode14(in, offset+i+9, res)
+	}
+	return false
+}
+
+// Const(", amount=", len=9)
+func findNode14(in string, offset int, res *Result) bool {
+	// Param("{amount}")
+	{
+		res.values[res.num] = in[offset:]
+		res.num++
+		res.Name = "order"
+		return true
+	}
+}
+
+// Const("user ", len=5)
+func findNode17(in string, offset int, res *Result) bool {
+	// Param("{name}")
+	if i := strings.Index(in[offset:], " logged in from "); i >= 0 {
+		res.values[res.num] = in[offset : offset+i]
+		res.num++
+		return findNode19(in, offset+i+16, res)
+	} else if i := strings.Index(in[offset:], " logged out"); i >= 0 {
+		res.values[res.num] = in[offset : offset+i]
+		res.num++
+		return findNode24(in, offset+i+11, res)
+	}
+	return false
+}
+
+// Const(" logged in from ", len=16)
+func findNode19(in string, offset int, res *Result) bool {
+	// Param("{ip}")
+	if i := strings.Index(in[offset:], " port "); i >= 0 {
+		res.values[res.num] = in[offset : offset+i]
+		res.num++
+		return findNode21(in, offset+i+6, res)
+	}
+	return false
+}
+
+// Const(" port ", len=6)
+func findNode21(in string, offset int, res *Result) bool {
+	// Param("{port}")
+	{
+		res.values[res.num] = in[offset:]
+		res.num++
+		res.Name = "login"
+		return true
+	}
+}
+
+// Const(" logged out", len=11)
+func findNode24(in string, offset int, res *Result) bool {
+	// END("logout")
+	if len(in) == offset {
+		res.Name = "logout"
+		return true
+	}
+	return false
+}
+
+// Const("/ba", len=3)
+func findNode26(in string, offset int, res *Result) bool {
+	// Param("{rest}")
+	{
+		res.values[res.num] = in[offset:]
+		res.num++
+		res.Name = "ba"
+		return true
+	}
+}
+
+// Const("/b", len=2)
+func findNode29(in string, offset int, res *Result) bool {
+	// END("b")
+	if len(in) == offset {
+		res.Name = "b"
+		return true
+	}
+	return false
+}
+
+// Const("/", len=1)
+func findNode31(in string, offset int, res *Result) bool {
+	// END("index")
+	if len(in) == offset {
+		res.Name = "index"
+		return true
+	}
+	// Param("{path}")
+	{
+		res.values[res.num] = in[offset:]
+		res.num++
+		res.Name = "any"
+		return true
+	}
+}
@@ -0,0 +1,510 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"strings"
+	"unicode"
+
+	"github.com/pkg/errors"
+
+	"github.com/gebv/strparam"
+	"github.com/gebv/strparam/internal/patternfile"
+)
+
+// genPattern pattern prepared for generation.
+type genPattern struct {
+	Name    string
+	Source  string
+	Ident   string
+	Params  []genParam
+	Pattern *strparam.Pattern
+}
+
+type genParam struct {
+	Name  string
+	Field string
+}
+
+func preparePatterns(patterns []patternfile.Pattern) ([]genPattern, *strparam.Store, error) {
+	store := strparam.NewStore()
+	idents := map[string]string{}
+
+	res := make([]genPattern, 0, len(patterns))
+	for _, item := range patterns {
+		p, err := store.AddNamed(item.Name, item.Pattern)
+		if err != nil {
+			return nil, nil, errors.Wrapf(err, "pattern %q", item.Name)
+		}
+
+		ident := exportedIdent(item.Name)
+		if ident == "" {
+			return nil, nil, errors.Errorf("pattern %q: name cannot be converted to Go identifier", item.Name)
+		}
+		if ident == "Name" {
+			// conflicts with the field of Result
+			return nil, nil, errors.Errorf("pattern %q: Go identifier %q is reserved", item.Name, ident)
+		}
+		if other, exists := idents[ident]; exists {
+			return nil, nil, errors.Errorf("pattern %q: same Go identifier %q as for pattern %q", item.Name, ident, other)
+		}
+		idents[ident] = item.Name
+
+		gp := genPattern{
+			Name:    item.Name,
+			Source:  item.Pattern,
+			Ident:   ident,
+			Pattern: p,
+		}
+		fields := map[string]string{}
+		for _, t := range p.Tokens {
+			if t.Mode != strparam.PARAMETER {
+				continue
+			}
+			field := exportedIdent(t.ParamName())
+			if field == "" {
+				return nil, nil, errors.Errorf("pattern %q: name of parameter %q cannot be converted to Go identifier", item.Name, t.ParamName())
+			}
+			if other, exists := fields[field]; exists {
+				return nil, nil, errors.Errorf("pattern %q: parameter %q has same Go identifier %q as parameter %q", item.Name, t.ParamName(), field, other)
+			}
+			fields[field] = t.ParamName()
+			gp.Params = append(gp.Params, genParam{Name: t.ParamName(), Field: field})
+		}
+		res = append(res, gp)
+	}
+
+	return res, store, nil
+}
+
+// generate returns Go source of matchers for the patterns.
+func generate(pkgName string, patterns []patternfile.Pattern) ([]byte, error) {
+	gps, store, err := preparePatterns(patterns)
+	if err != nil {
+		return nil, err
+	}
+	tree, err := loadTree(store)
+	if err != nil {
+		return nil, err
+	}
+
+	maxParams := 0
+	for _, gp := range gps {
+		if len(gp.Params) > maxParams {
+			maxParams = len(gp.Params)
+		}
+	}
+
+	body := new(bytes.Buffer)
+
+	fmt.Fprintln(body, "// Names of patterns.")
+	fmt.Fprintln(body, "const (")
+	for _, gp := range gps {
+		fmt.Fprintf(body, "Pattern%s = %q\n", gp.Ident, gp.Name)
+	}
+	fmt.Fprintln(body, ")")
+
+	for _, gp := range gps {
+		writePatternMatcher(body, gp)
+	}
+
+	fmt.Fprintln(body, "// Result of Match.")
+	fmt.Fprintln(body, "type Result struct {")
+	fmt.Fprintln(body, "// Name of matched pattern.")
+	fmt.Fprintln(body, "Name string")
+	fmt.Fprintf(body, "values [%d]string\n", maxParams)
+	fmt.Fprintln(body, "num int")
+	fmt.Fprintln(body, "}")
+
+	for _, gp := range gps {
+		fmt.Fprintf(body, "\n// %s returns parameters of pattern %q if it is matched.\n", gp.Ident, gp.Name)
+		fmt.Fprintf(body, "func (r *Result) %s() (res %sParams, ok bool) {\n", gp.Ident, gp.Ident)
+		fmt.Fprintf(body, "if r.Name != Pattern%s {\nreturn res, false\n}\n", gp.Ident)
+		for i, param := range gp.Params {
+			fmt.Fprintf(body, "res.%s = r.values[%d]\n", param.Field, i)
+		}
+		fmt.Fprintln(body, "return res, true")
+		fmt.Fprintln(body, "}")
+	}
+
+	fmt.Fprintln(body, "\n// Match returns the matched pattern and its parameters (same as strparam.Store.Find and then strparam.Pattern.Lookup).")
+	fmt.Fprintln(body, "func Match(in string) (res Result, ok bool) {")
+	fmt.Fprintln(body, "if !findNode0(in, 0, &res) {\nreturn Result{}, false\n}")
+	fmt.Fprintln(body, "return res, true")
+	fmt.Fprintln(body, "}")
+
+	g := &treeGen{w: body}
+	g.writeNode(tree, 0)
+
+	return formatSource(pkgName, body.Bytes(), nil)
+}
+
+// writePatternMatcher writes the struct of parameters and matching function of the pattern.
+//
+// The matching function is the same as strparam.Pattern.Lookup.
+func writePatternMatcher(w *bytes.Buffer, gp genPattern) {
+	fmt.Fprintf(w, "\n// %sParams parameters of pattern %q: %s\n", gp.Ident, gp.Name, gp.Source)
+	fmt.Fprintf(w, "type %sParams struct {\n", gp.Ident)
+	for _, param := range gp.Params {
+		fmt.Fprintf(w, "%s string // %s\n", param.Field, param.Name)
+	}
+	fmt.Fprintln(w, "}")
+
+	fmt.Fprintf(w, "\n// Match%s returns parameters if the input string matched to pattern %q (same as strparam.Pattern.Lookup).\n", gp.Ident, gp.Name)
+	fmt.Fprintf(w, "func Match%s(in string) (res %sParams, ok bool) {\n", gp.Ident, gp.Ident)
+	fmt.Fprintln(w, "offset := 0")
+
+	tokens := gp.Pattern.Tokens
+	for num, t := range tokens {
+		if t.Mode == strparam.PARAMETER && tokens[num+1].Mode != strparam.END {
+			fmt.Fprintln(w, "var i int")
+			break
+		}
+	}
+
+	numParam := 0
+	for num, t := range tokens {
+		switch t.Mode {
+		case strparam.CONST, strparam.SEPARATOR:
+			fmt.Fprintf(w, "if !strings.HasPrefix(in[offset:], %q) {\nreturn res, false\n}\n", t.Raw)
+			fmt.Fprintf(w, "offset += %d\n", t.Len)
+		case strparam.PARAMETER:
+			field := gp.Params[numParam].Field
+			numParam++
+
+			next := tokens[num+1]
+			if next.Mode == strparam.END {
+				fmt.Fprintf(w, "res.%s = in[offset:]\n", field)
+				fmt.Fprintln(w, "offset = len(in)")
+				continue
+			}
+			fmt.Fprintf(w, "i = strings.Index(in[offset:], %q)\n", next.Raw)
+			fmt.Fprintln(w, "if i < 0 {\nreturn res, false\n}")
+			fmt.Fprintf(w, "res.%s = in[offset : offset+i]\n", field)
+			fmt.Fprintln(w, "offset += i")
+		}
+	}
+
+	fmt.Fprintln(w, "return res, offset == len(in)")
+	fmt.Fprintln(w, "}")
+}
+
+// treeGen writes the functions of walking by the tree (same as strparam.Store.Find).
+//
+// A function per node of tree (except END and PARAMETER nodes), the nodes are numbered in pre-order.
+type treeGen struct {
+	w *bytes.Buffer
+}
+
+func (g *treeGen) writeNode(n *treeNode, idx int) {
+	// numbers of child nodes
+	childIdx := make([]int, len(n.Childs))
+	next := idx + 1
+	for i, child := range n.Childs {
+		childIdx[i] = next
+		next += countNodes(child)
+	}
+
+	w := g.w
+	if n.Token.Mode == strparam.UNKNOWN_TokenMode {
+		fmt.Fprintln(w, "\n// root")
+	} else {
+		fmt.Fprintf(w, "\n// %s\n", n.Token.String())
+	}
+	fmt.Fprintf(w, "func findNode%d(in string, offset int, res *Result) bool {\n", idx)
+
+	terminated := false
+	for i, child := range n.Childs {
+		if terminated {
+			break
+		}
+		notLast := len(n.Childs)-1 > i
+
+		switch child.Token.Mode {
+		case strparam.START:
+			fmt.Fprintf(w, "return findNode%d(in, offset, res)\n", childIdx[i])
+			terminated = true
+		case strparam.END:
+			fmt.Fprintf(w, "// %s\n", child.Token.String())
+			fmt.Fprintf(w, "if len(in) == offset {\nres.Name = %q\nreturn true\n}\n", child.Token.Raw)
+		case strparam.CONST, strparam.SEPARATOR:
+			g.writeConstChild(child, childIdx[i])
+		case strparam.PARAMETER:
+			terminated = g.writeParamChild(child, childIdx[i], notLast)
+		}
+	}
+
+	if !terminated {
+		fmt.Fprintln(w, "return false")
+	}
+	fmt.Fprintln(w, "}")
+
+	for i, child := range n.Childs {
+		switch child.Token.Mode {
+		case strparam.END:
+		case strparam.PARAMETER:
+			// the walk jumps over the parameter to the next node
+			next := childIdx[i] + 1
+			for _, nextNode := range child.Childs {
+				if nextNode.Token.Mode != strparam.END {
+					g.writeNode(nextNode, next)
+				}
+				next += countNodes(nextNode)
+			}
+		default:
+			g.writeNode(child, childIdx[i])
+		}
+	}
+}
+
+func (g *treeGen) writeConstChild(child *treeNode, idx int) {
+	w := g.w
+	t := child.Token
+	fmt.Fprintf(w, "// %s\n", t.String())
+
+	cond := fmt.Sprintf("offset+%d <= len(in)", t.Len)
+	if nextSingleEnd(child) {
+		// the tail must match exactly
+		cond = fmt.Sprintf("offset+%d == len(in)", t.Len)
+	}
+	fmt.Fprintf(w, "if %s && in[offset:offset+%d] == %q {\n", cond, t.Len, t.Raw)
+
+	if nextHas(child, strparam.PARAMETER) {
+		fmt.Fprintf(w, "return findNode%d(in, offset+%d, res)\n", idx, t.Len)
+	} else {
+		conds := []string{fmt.Sprintf("offset+%d == len(in)", t.Len)}
+		for _, next := range child.Childs {
+			if next.Token.Mode == strparam.CONST || next.Token.Mode == strparam.SEPARATOR {
+				conds = append(conds, fmt.Sprintf("strings.HasPrefix(in[offset+%d:], %q)", t.Len, next.Token.Raw))
+			}
+		}
+		fmt.Fprintf(w, "if %s {\nreturn findNode%d(in, offset+%d, res)\n}\n", strings.Join(conds, " || "), idx, t.Len)
+	}
+
+	fmt.Fprintln(w, "}")
+}
+
+// writeParamChild returns true if written code is terminating statement.
+func (g *treeGen) writeParamChild(child *treeNode, idx int, notLast bool) bool {
+	w := g.w
+	fmt.Fprintf(w, "// %s\n", child.Token.String())
+
+	// the next node defines the end of parameter (first matched)
+	nextIdx := idx + 1
+	first := true
+	for _, next := range child.Childs {
+		switch next.Token.Mode {
+		case strparam.CONST, strparam.SEPARATOR:
+			if !first {
+				fmt.Fprint(w, " else ")
+			}
+			first = false
+			fmt.Fprintf(w, "if i := strings.Index(in[offset:], %q); i >= 0 {\n", next.Token.Raw)
+			if notLast {
+				fmt.Fprintln(w, "if i != 0 {")
+			}
+			fmt.Fprintln(w, "res.values[res.num] = in[offset : offset+i]")
+			fmt.Fprintln(w, "res.num++")
+			fmt.Fprintf(w, "return findNode%d(in, offset+i+%d, res)\n", nextIdx, next.Token.Len)
+			if notLast {
+				fmt.Fprintln(w, "}")
+			}
+			fmt.Fprint(w, "}")
+			nextIdx += countNodes(next)
+		case strparam.END:
+			if first {
+				fmt.Fprintln(w, "{")
+			} else {
+				fmt.Fprintln(w, " else {")
+			}
+			if notLast {
+				fmt.Fprintln(w, "if len(in) != offset {")
+			}
+			fmt.Fprintln(w, "res.values[res.num] = in[offset:]")
+			fmt.Fprintln(w, "res.num++")
+			fmt.Fprintf(w, "res.Name = %q\n", next.Token.Raw)
+			fmt.Fprintln(w, "return true")
+			if notLast {
+				fmt.Fprintln(w, "}")
+			}
+			fmt.Fprintln(w, "}")
+			// returns tail, the next nodes are not checked
+			return !notLast
+		}
+	}
+	fmt.Fprintln(w)
+	return false
+}
+
+// generateTest returns Go source of test that checks generated code against the runtime strparam.Store.
+func generateTest(pkgName string, patterns []patternfile.Pattern, samples []string) ([]byte, error) {
+	gps, _, err := preparePatterns(patterns)
+	if err != nil {
+		return nil, err
+	}
+
+	body := new(bytes.Buffer)
+	fmt.Fprintln(body, "var strparamGenPatterns = [][2]string{")
+	for _, gp := range gps {
+		fmt.Fprintf(body, "{%q, %q},\n", gp.Name, gp.Source)
+	}
+	fmt.Fprintln(body, "}")
+
+	fmt.Fprintln(body, "\nvar strparamGenSamples = []string{")
+	for _, sample := range samples {
+		fmt.Fprintf(body, "%q,\n", sample)
+	}
+	fmt.Fprintln(body, "}")
+
+	fmt.Fprintln(body, "\nfunc strparamGenLookup(name, in string) ([]string, bool) {")
+	fmt.Fprintln(body, "switch name {")
+	for _, gp := range gps {
+		fmt.Fprintf(body, "case Pattern%s:\n", gp.Ident)
+		if len(gp.Params) == 0 {
+			fmt.Fprintf(body, "_, ok := Match%s(in)\n", gp.Ident)
+		} else {
+			fmt.Fprintf(body, "res, ok := Match%s(in)\n", gp.Ident)
+		}
+		fields := make([]string, 0, len(gp.Params))
+		for _, param := range gp.Params {
+			fields = append(fields, "res."+param.Field)
+		}
+		fmt.Fprintf(body, "return []string{%s}, ok\n", strings.Join(fields, ", "))
+	}
+	fmt.Fprintln(body, "}")
+	fmt.Fprintln(body, "return nil, false")
+	fmt.Fprintln(body, "}")
+
+	body.WriteString(generatedTestFunc)
+
+	return formatSource(pkgName, body.Bytes(), []string{"reflect", "testing", "", "github.com/gebv/strparam"})
+}
+
+const generatedTestFunc = `
+func TestStrparamGen(t *testing.T) {
+	store := strparam.NewStore()
+	patterns := map[string]*strparam.Pattern{}
+	for _, item := range strparamGenPatterns {
+		p, err := store.AddNamed(item[0], item[1])
+		if err != nil {
+			t.Fatal(err)
+		}
+		patterns[item[0]] = p
+	}
+
+	for _, in := range strparamGenSamples {
+		wantName, wantOK, want := "", false, []string{}
+		if found := store.Find(in); found != nil {
+			if matched, params := found.Lookup(in); matched {
+				wantName, wantOK = found.Name(), true
+				for _, param := range params {
+					want = append(want, param.Value)
+				}
+			}
+		}
+
+		res, ok := Match(in)
+		got := append([]string{}, res.values[:res.num]...)
+		if ok != wantOK || res.Name != wantName || !reflect.DeepEqual(got, want) {
+			t.Errorf("Match(%q) = %q %v %q, runtime store %q %v %q", in, res.Name, ok, got, wantName, wantOK, want)
+		}
+
+		for _, item := range strparamGenPatterns {
+			wantOK, want := false, []string{}
+			matched, params := patterns[item[0]].Lookup(in)
+			if matched {
+				wantOK = true
+				for _, param := range params {
+					want = append(want, param.Value)
+				}
+			}
+
+			got, ok := strparamGenLookup(item[0], in)
+			if ok != wantOK || (ok && !reflect.DeepEqual(append([]string{}, got...), want)) {
+				t.Errorf("pattern %q: match %q = %v %q, runtime pattern %v %q", item[0], in, ok, got, wantOK, want)
+			}
+		}
+	}
+}
+`
+
+// formatSource returns formatted Go source with header and imports.
+func formatSource(pkgName string, body []byte, imports []string) ([]byte, error) {
+	if imports == nil && bytes.Contains(body, []byte("strings.")) {
+		imports = []string{"strings"}
+	}
+
+	src := new(bytes.Buffer)
+	fmt.Fprintln(src, "// Code generated by strparam-gen. DO NOT EDIT.")
+	fmt.Fprintf(src, "\npackage %s\n", pkgName)
+	if len(imports) > 0 {
+		fmt.Fprintln(src, "\nimport (")
+		for _, imp := range imports {
+			if imp == "" {
+				fmt.Fprintln(src)
+				continue
+			}
+			fmt.Fprintf(src, "%q\n", imp)
+		}
+		fmt.Fprintln(src, ")")
+	}
+	fmt.Fprintln(src)
+	src.Write(body)
+
+	res, err := format.Source(src.Bytes())
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed format generated code:\n%s", src.String())
+	}
+	return res, nil
+}
+
+// exportedIdent returns exported Go identifier (in CamelCase) from the name.
+func exportedIdent(name string) string {
+	res := new(strings.Builder)
+	upper := true
+	for _, r := range name {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			upper = true
+			continue
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		res.WriteRune(r)
+	}
+	if res.Len() == 0 {
+		return ""
+	}
+
+	ident := res.String()
+	for _, r := range ident {
+		if !unicode.IsUpper(r) {
+			ident = "P" + ident
+		}
+		break
+	}
+	return ident
+}
+
+func countNodes(n *treeNode) int {
+	res := 1
+	for _, child := range n.Childs {
+		res += countNodes(child)
+	}
+	return res
+}
+
+func nextSingleEnd(n *treeNode) bool {
+	return len(n.Childs) == 1 && n.Childs[0].Token.Mode == strparam.END
+}
+
+func nextHas(n *treeNode, mode strparam.TokenMode) bool {
+	for _, child := range n.Childs {
+		if child.Token.Mode == mode {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,112 @@
+// Command strparam-gen generates Go source with specialized matchers for a list of named patterns.
+//
+// Usage:
+//
+//	strparam-gen -i patterns.txt -o patterns_gen.go [-pkg name] [-samples samples.txt]
+//
+// or via go generate
+//
+//	//go:generate strparam-gen -i patterns.txt -o patterns_gen.go -samples samples.txt
+//
+// The file of patterns contains a named pattern per line: name and pattern separated by whitespace
+// (see package internal/patternfile).
+//
+// Generated code contains
+// - a struct of parameters and a matching function per pattern (same as strparam.Pattern.Lookup)
+// - the dispatcher Match (same as strparam.Store.Find and then strparam.Pattern.Lookup)
+//
+// If sets the file of samples (an input string per line) then also generates a test
+// that checks generated code against the runtime strparam.Store on the samples.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"io"
+	"io/ioutil"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/gebv/strparam/internal/patternfile"
+)
+
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("strparam-gen: ")
+
+	var (
+		inFile      = flag.String("i", "", "file of named patterns (name and pattern per line)")
+		outFile     = flag.String("o", "", "output file (default stdout)")
+		pkgName     = flag.String("pkg", os.Getenv("GOPACKAGE"), "package name of generated code (default $GOPACKAGE)")
+		samplesFile = flag.String("samples", "", "file of sample inputs (input per line) for generated test")
+	)
+	flag.Parse()
+
+	if err := run(*inFile, *outFile, *pkgName, *samplesFile); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func run(inFile, outFile, pkgName, samplesFile string) error {
+	if inFile == "" {
+		return errors.New("file of patterns is required (-i)")
+	}
+	if pkgName == "" {
+		return errors.New("package name is required (-pkg)")
+	}
+	if samplesFile != "" && outFile == "" {
+		return errors.New("output file is required for generated test (-o)")
+	}
+
+	patterns, err := patternfile.ReadFile(inFile)
+	if err != nil {
+		return err
+	}
+
+	src, err := generate(pkgName, patterns)
+	if err != nil {
+		return errors.Wrap(err, "failed generate")
+	}
+
+	if outFile == "" {
+		_, err = os.Stdout.Write(src)
+		return err
+	}
+	if err := ioutil.WriteFile(outFile, src, 0644); err != nil {
+		return err
+	}
+
+	if samplesFile == "" {
+		return nil
+	}
+
+	sf, err := os.Open(samplesFile)
+	if err != nil {
+		return err
+	}
+	defer sf.Close()
+
+	samples, err := readSamples(sf)
+	if err != nil {
+		return errors.Wrapf(err, "failed read %q", samplesFile)
+	}
+
+	testSrc, err := generateTest(pkgName, patterns, samples)
+	if err != nil {
+		return errors.Wrap(err, "failed generate test")
+	}
+
+	return ioutil.WriteFile(strings.TrimSuffix(outFile, ".go")+"_test.go", testSrc, 0644)
+}
+
+func readSamples(r io.Reader) ([]string, error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	data = bytes.TrimSuffix(data, []byte("\n"))
+	return strings.Split(string(data), "\n"), nil
+}
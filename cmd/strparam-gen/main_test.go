@@ -0,0 +1,54 @@
+package main
+
+import (
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gebv/strparam/internal/patternfile"
+)
+
+func Test_generate_Errors(t *testing.T) {
+	_, err := generate("example", []patternfile.Pattern{{Name: "a", Pattern: "{}"}})
+	require.EqualError(t, err, `pattern "a": failed parse: empty name of parameter, pos 1`)
+	_, err = generate("example", []patternfile.Pattern{{Name: "a_b", Pattern: "a"}, {Name: "a-b", Pattern: "b"}})
+	require.EqualError(t, err, `pattern "a-b": same Go identifier "AB" as for pattern "a_b"`)
+	_, err = generate("example", []patternfile.Pattern{{Name: "a", Pattern: "{p_1}/{p-1}"}})
+	require.EqualError(t, err, `pattern "a": parameter "p-1" has same Go identifier "P1" as parameter "p_1"`)
+	_, err = generate("example", []patternfile.Pattern{{Name: "name", Pattern: "a"}})
+	require.EqualError(t, err, `pattern "name": Go identifier "Name" is reserved`)
+}
+
+func Test_generate_Example(t *testing.T) {
+	// generated code of the example should be up to date
+	patterns, err := patternfile.ReadFile("example/patterns.txt")
+	require.NoError(t, err)
+
+	got, err := generate("example", patterns)
+	require.NoError(t, err)
+	want, err := ioutil.ReadFile("example/patterns_gen.go")
+	require.NoError(t, err)
+	assert.Equal(t, string(want), string(got))
+}
+
+func Test_exportedIdent(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"", ""},
+		{"-", ""},
+		{"foo", "Foo"},
+		{"foo_bar", "FooBar"},
+		{"foo-bar.baz", "FooBarBaz"},
+		{"fooBar", "FooBar"},
+		{"1foo", "P1foo"},
+		{"日本語", "P日本語"},
+		{"сыр", "Сыр"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, exportedIdent(tt.in), tt.in)
+	}
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"encoding/json"
+
+	"github.com/pkg/errors"
+
+	"github.com/gebv/strparam"
+)
+
+// treeNode copy of node of the sorted tree of strparam.Store.
+type treeNode struct {
+	Token  strparam.Token
+	Childs []*treeNode
+}
+
+// jsonTreeNode node of the tree exported by strparam.Store.MarshalJSON.
+type jsonTreeNode struct {
+	Mode   string          `json:"mode"`
+	Raw    string          `json:"raw"`
+	Name   string          `json:"name"`
+	Childs []*jsonTreeNode `json:"childs"`
+}
+
+// modes of tokens by names in JSON (the root node is "root")
+var jsonTreeModes = map[string]strparam.TokenMode{
+	"root": strparam.UNKNOWN_TokenMode,
+}
+
+func init() {
+	for _, mode := range []strparam.TokenMode{strparam.START, strparam.END, strparam.CONST, strparam.SEPARATOR, strparam.PARAMETER} {
+		jsonTreeModes[mode.String()] = mode
+	}
+}
+
+// loadTree returns the sorted tree of patterns from the storage.
+//
+// The tree is read from the JSON export of storage (see strparam.Store.MarshalJSON).
+func loadTree(store *strparam.Store) (*treeNode, error) {
+	data, err := store.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+	root := &jsonTreeNode{}
+	if err := json.Unmarshal(data, root); err != nil {
+		return nil, errors.Wrap(err, "failed decode tree of storage")
+	}
+	return newTreeNode(root)
+}
+
+func newTreeNode(n *jsonTreeNode) (*treeNode, error) {
+	mode, ok := jsonTreeModes[n.Mode]
+	if !ok {
+		return nil, errors.Errorf("not supported token type %q", n.Mode)
+	}
+
+	res := &treeNode{Token: strparam.Token{Mode: mode, Raw: n.Raw}}
+	switch mode {
+	case strparam.CONST, strparam.SEPARATOR:
+		res.Token.Len = len(n.Raw)
+	case strparam.END:
+		// name of pattern
+		res.Token.Raw = n.Name
+	}
+
+	for _, child := range n.Childs {
+		childNode, err := newTreeNode(child)
+		if err != nil {
+			return nil, err
+		}
+		res.Childs = append(res.Childs, childNode)
+	}
+	return res, nil
+}
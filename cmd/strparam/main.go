@@ -0,0 +1,59 @@
+// Command strparam matches lines of text by patterns and extracts parameters.
+//
+// Usage:
+//
+//	strparam match -p 'foo={p1}, bar={p2}' < file
+//	strparam match -f patterns.txt [-format json|csv] [-unmatched skip|print|stderr] [-count] [file ...]
+//	strparam test [-v] spec.yaml ...
+//
+// Exit codes of match: 0 if at least one line is matched, 1 if no lines are matched, 2 if error.
+// Exit codes of test: 0 if all examples are passed, 1 if some examples are failed, 2 if error.
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+)
+
+// exit codes (exitOK also if at least one line is matched)
+const (
+	exitOK        = 0
+	exitNoMatches = 1
+	exitError     = 2
+
+	exitTestFailed = 1
+)
+
+const usage = `Usage: strparam <command> [flags]
+
+Commands:
+  match    match lines of input by patterns and print parameters
+  test     check examples of test-spec files of patterns
+
+Run 'strparam <command> -h' for details of the command.
+`
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprint(stderr, usage)
+		return exitError
+	}
+
+	switch args[0] {
+	case "match":
+		return runMatch(args[1:], stdin, stdout, stderr)
+	case "test":
+		return runTest(args[1:], stdout, stderr)
+	case "-h", "-help", "--help", "help":
+		fmt.Fprint(stdout, usage)
+		return exitOK
+	}
+
+	fmt.Fprintf(stderr, "strparam: unknown command %q\n\n%s", args[0], usage)
+	return exitError
+}
@@ -0,0 +1,354 @@
+package main
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/gebv/strparam"
+	"github.com/gebv/strparam/internal/patternfile"
+)
+
+// max length of input line
+const maxLineSize = 1 << 20
+
+// modes of output unmatched lines
+const (
+	unmatchedSkip   = "skip"
+	unmatchedPrint  = "print"
+	unmatchedStderr = "stderr"
+)
+
+// patternsFlag repeatable flag of patterns.
+type patternsFlag []string
+
+func (f *patternsFlag) String() string {
+	return strings.Join(*f, ", ")
+}
+
+func (f *patternsFlag) Set(v string) error {
+	*f = append(*f, v)
+	return nil
+}
+
+// runMatch runs the command match, returns exit code.
+func runMatch(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("match", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	var patterns patternsFlag
+	fs.Var(&patterns, "p", "pattern (repeatable), the name of pattern is the pattern itself")
+	patternsFile := fs.String("f", "", "file of named patterns (name and pattern per line)")
+	format := fs.String("format", "json", "output format: json (JSON Lines) or csv")
+	unmatched := fs.String("unmatched", unmatchedSkip, "output of unmatched lines: skip, print (as record of output) or stderr (as is)")
+	count := fs.Bool("count", false, "print summary of counts to stderr")
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: strparam match [flags] [file ...]")
+		fmt.Fprintln(stderr, "\nMatches lines of files (or stdin) by patterns and prints parameters.")
+		fmt.Fprintln(stderr, "Exit codes: 0 if at least one line is matched, 1 if no lines are matched, 2 if error.")
+		fmt.Fprintln(stderr, "\nFlags:")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return exitOK
+		}
+		return exitError
+	}
+
+	fail := func(format string, args ...interface{}) int {
+		fmt.Fprintf(stderr, "strparam match: "+format+"\n", args...)
+		return exitError
+	}
+
+	if *format != "json" && *format != "csv" {
+		return fail("unknown format %q", *format)
+	}
+	if *unmatched != unmatchedSkip && *unmatched != unmatchedPrint && *unmatched != unmatchedStderr {
+		return fail("unknown mode of unmatched lines %q", *unmatched)
+	}
+
+	var list []patternfile.Pattern
+	for _, pattern := range patterns {
+		list = append(list, patternfile.Pattern{Name: pattern, Pattern: pattern})
+	}
+	if *patternsFile != "" {
+		fromFile, err := patternfile.ReadFile(*patternsFile)
+		if err != nil {
+			return fail("%v", err)
+		}
+		list = append(list, fromFile...)
+	}
+	if len(list) == 0 {
+		return fail("patterns are required (-p or -f)")
+	}
+
+	m := &matcher{
+		store:     strparam.NewStore(),
+		counts:    map[string]int{},
+		unmatched: *unmatched,
+		stderr:    stderr,
+	}
+	columns := []string{}
+	knownColumns := map[string]bool{}
+	for _, item := range list {
+		p, err := m.store.AddNamed(item.Name, item.Pattern)
+		if err != nil {
+			return fail("pattern %q: %v", item.Name, err)
+		}
+		m.names = append(m.names, item.Name)
+		var names []string
+		for _, t := range p.Tokens {
+			if t.Mode == strparam.PARAMETER {
+				names = append(names, t.ParamName())
+			}
+		}
+		for _, column := range paramColumns(names) {
+			if !knownColumns[column] {
+				knownColumns[column] = true
+				columns = append(columns, column)
+			}
+		}
+	}
+
+	out := bufio.NewWriter(stdout)
+	if *format == "csv" {
+		m.out = newCSVOutput(out, columns, *unmatched == unmatchedPrint)
+	} else {
+		m.out = &jsonOutput{w: out}
+	}
+
+	inputs := fs.Args()
+	if len(inputs) == 0 {
+		inputs = []string{"-"}
+	}
+	for _, input := range inputs {
+		if err := m.matchInput(input, stdin); err != nil {
+			out.Flush()
+			return fail("%v", err)
+		}
+	}
+
+	if err := m.out.Flush(); err != nil {
+		return fail("%v", err)
+	}
+	if err := out.Flush(); err != nil {
+		return fail("%v", err)
+	}
+
+	if *count {
+		m.writeSummary(stderr)
+	}
+
+	if m.matched == 0 {
+		return exitNoMatches
+	}
+	return exitOK
+}
+
+// matcher matches lines by patterns and writes results.
+type matcher struct {
+	store     *strparam.Store
+	names     []string
+	out       output
+	unmatched string
+	stderr    io.Writer
+
+	lines   int
+	matched int
+	counts  map[string]int
+}
+
+// matchInput matches lines of file (or stdin if "-").
+func (m *matcher) matchInput(input string, stdin io.Reader) error {
+	r := stdin
+	if input != "-" {
+		f, err := os.Open(input)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		r = f
+	}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLineSize)
+	for scanner.Scan() {
+		if err := m.matchLine(strings.TrimSuffix(scanner.Text(), "\r")); err != nil {
+			return err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed read %q: %v", input, err)
+	}
+	return nil
+}
+
+func (m *matcher) matchLine(line string) error {
+	m.lines++
+
+	if found := m.store.Find(line); found != nil {
+		if matched, params := found.Lookup(line); matched {
+			m.matched++
+			m.counts[found.Name()]++
+			return m.out.Matched(m.lines, found.Name(), params)
+		}
+	}
+
+	switch m.unmatched {
+	case unmatchedPrint:
+		return m.out.Unmatched(m.lines, line)
+	case unmatchedStderr:
+		_, err := fmt.Fprintln(m.stderr, line)
+		return err
+	}
+	return nil
+}
+
+func (m *matcher) writeSummary(w io.Writer) {
+	fmt.Fprintf(w, "lines: %d\n", m.lines)
+	fmt.Fprintf(w, "matched: %d\n", m.matched)
+	fmt.Fprintf(w, "unmatched: %d\n", m.lines-m.matched)
+	for _, name := range m.names {
+		fmt.Fprintf(w, "pattern %q: %d\n", name, m.counts[name])
+	}
+}
+
+// output writer of results.
+type output interface {
+	Matched(line int, pattern string, params strparam.Params) error
+	Unmatched(line int, in string) error
+	Flush() error
+}
+
+// jsonOutput writes JSON Lines
+//
+//	{"line":1,"matched":true,"pattern":"name","params":[{"name":"p1","value":"value"}]}
+//	{"line":2,"matched":false,"input":"unmatched line"}
+//
+// Parameters are in order of the pattern (names of parameters may repeat).
+type jsonOutput struct {
+	w io.Writer
+}
+
+func (o *jsonOutput) Matched(line int, pattern string, params strparam.Params) error {
+	buf := new(strings.Builder)
+	fmt.Fprintf(buf, `{"line":%d,"matched":true,"pattern":%s,"params":[`, line, jsonString(pattern))
+	for i, param := range params {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		fmt.Fprintf(buf, `{"name":%s,"value":%s}`, jsonString(param.Name), jsonString(param.Value))
+	}
+	buf.WriteString("]}\n")
+	_, err := io.WriteString(o.w, buf.String())
+	return err
+}
+
+func (o *jsonOutput) Unmatched(line int, in string) error {
+	_, err := fmt.Fprintf(o.w, `{"line":%d,"matched":false,"input":%s}`+"\n", line, jsonString(in))
+	return err
+}
+
+func (o *jsonOutput) Flush() error {
+	return nil
+}
+
+func jsonString(v string) string {
+	res, _ := json.Marshal(v)
+	return string(res)
+}
+
+// csvOutput writes CSV with header: line, pattern, [input], parameters of all patterns (see paramColumns).
+type csvOutput struct {
+	w         *csv.Writer
+	columns   map[string]int
+	numFields int
+	withInput bool
+	header    []string
+}
+
+func newCSVOutput(w io.Writer, columns []string, withInput bool) *csvOutput {
+	o := &csvOutput{
+		w:         csv.NewWriter(w),
+		columns:   map[string]int{},
+		withInput: withInput,
+		header:    []string{"line", "pattern"},
+	}
+	if withInput {
+		o.header = append(o.header, "input")
+	}
+	for _, column := range columns {
+		o.columns[column] = len(o.header)
+		o.header = append(o.header, column)
+	}
+	o.numFields = len(o.header)
+	return o
+}
+
+func (o *csvOutput) writeHeader() error {
+	if o.header == nil {
+		return nil
+	}
+	header := o.header
+	o.header = nil
+	return o.w.Write(header)
+}
+
+func (o *csvOutput) Matched(line int, pattern string, params strparam.Params) error {
+	if err := o.writeHeader(); err != nil {
+		return err
+	}
+	record := make([]string, o.numFields)
+	record[0], record[1] = strconv.Itoa(line), pattern
+	names := make([]string, len(params))
+	for i, param := range params {
+		names[i] = param.Name
+	}
+	for i, column := range paramColumns(names) {
+		record[o.columns[column]] = params[i].Value
+	}
+	return o.w.Write(record)
+}
+
+func (o *csvOutput) Unmatched(line int, in string) error {
+	if err := o.writeHeader(); err != nil {
+		return err
+	}
+	record := make([]string, o.numFields)
+	record[0] = strconv.Itoa(line)
+	if o.withInput {
+		record[2] = in
+	}
+	return o.w.Write(record)
+}
+
+func (o *csvOutput) Flush() error {
+	if err := o.writeHeader(); err != nil {
+		return err
+	}
+	o.w.Flush()
+	return o.w.Error()
+}
+
+// paramColumns returns CSV columns of parameters by names in order of the pattern:
+// param:name (prefixed so as not to collide with line, pattern and input),
+// repeated name gets the number of occurrence param:name#2.
+func paramColumns(names []string) []string {
+	res := make([]string, len(names))
+	seen := map[string]int{}
+	for i, name := range names {
+		seen[name]++
+		if seen[name] == 1 {
+			res[i] = "param:" + name
+		} else {
+			res[i] = fmt.Sprintf("param:%s#%d", name, seen[name])
+		}
+	}
+	return res
+}
@@ -0,0 +1,176 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const matchTestInput = `user bob logged in from 10.0.0.1 port 22
+something else
+user "a,b" logged out
+user alice logged in from 10.0.0.2 port 2222
+`
+
+func runCmd(t *testing.T, stdin string, args ...string) (int, string, string) {
+	t.Helper()
+	stdout, stderr := new(bytes.Buffer), new(bytes.Buffer)
+	code := run(args, strings.NewReader(stdin), stdout, stderr)
+	return code, stdout.String(), stderr.String()
+}
+
+func writePatternsFile(t *testing.T) string {
+	t.Helper()
+	dir, err := ioutil.TempDir("", "strparam")
+	require.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	path := filepath.Join(dir, "patterns.txt")
+	require.NoError(t, ioutil.WriteFile(path, []byte("# patterns\nlogin user {name} logged in from {ip} port {port}\nlogout user {name} logged out\n"), 0644))
+	return path
+}
+
+func TestMatch_JSON(t *testing.T) {
+	code, stdout, stderr := runCmd(t, matchTestInput, "match", "-f", writePatternsFile(t))
+	assert.Equal(t, exitOK, code)
+	assert.Equal(t, "", stderr)
+	assert.Equal(t, `{"line":1,"matched":true,"pattern":"login","params":[{"name":"name","value":"bob"},{"name":"ip","value":"10.0.0.1"},{"name":"port","value":"22"}]}
+{"line":3,"matched":true,"pattern":"logout","params":[{"name":"name","value":"\"a,b\""}]}
+{"line":4,"matched":true,"pattern":"login","params":[{"name":"name","value":"alice"},{"name":"ip","value":"10.0.0.2"},{"name":"port","value":"2222"}]}
+`, stdout)
+}
+
+func TestMatch_Pattern(t *testing.T) {
+	code, stdout, _ := runCmd(t, "foo=1, bar=2\nfoo=3\n", "match", "-p", "foo={p1}, bar={p2}", "-p", "foo={p1}")
+	assert.Equal(t, exitOK, code)
+	assert.Equal(t, `{"line":1,"matched":true,"pattern":"foo={p1}, bar={p2}","params":[{"name":"p1","value":"1"},{"name":"p2","value":"2"}]}
+{"line":2,"matched":true,"pattern":"foo={p1}","params":[{"name":"p1","value":"3"}]}
+`, stdout)
+}
+
+func TestMatch_CSV(t *testing.T) {
+	code, stdout, _ := runCmd(t, matchTestInput, "match", "-f", writePatternsFile(t), "-format", "csv")
+	assert.Equal(t, exitOK, code)
+	assert.Equal(t, `line,pattern,param:name,param:ip,param:port
+1,login,bob,10.0.0.1,22
+3,logout,"""a,b""",,
+4,login,alice,10.0.0.2,2222
+`, stdout)
+
+	code, stdout, _ = runCmd(t, matchTestInput, "match", "-f", writePatternsFile(t), "-format", "csv", "-unmatched", "print")
+	assert.Equal(t, exitOK, code)
+	assert.Equal(t, `line,pattern,input,param:name,param:ip,param:port
+1,login,,bob,10.0.0.1,22
+2,,something else,,,
+3,logout,,"""a,b""",,
+4,login,,alice,10.0.0.2,2222
+`, stdout)
+
+	// header is written even without records
+	code, stdout, _ = runCmd(t, "", "match", "-p", "{a}", "-format", "csv")
+	assert.Equal(t, exitNoMatches, code)
+	assert.Equal(t, "line,pattern,param:a\n", stdout)
+}
+
+func TestMatch_RepeatedParamNames(t *testing.T) {
+	code, stdout, _ := runCmd(t, "1-2 x\n", "match", "-p", "{p}-{p} {line}")
+	assert.Equal(t, exitOK, code)
+	assert.Equal(t, `{"line":1,"matched":true,"pattern":"{p}-{p} {line}","params":[{"name":"p","value":"1"},{"name":"p","value":"2"},{"name":"line","value":"x"}]}
+`, stdout)
+
+	// columns of parameters do not collide with fixed columns and with each other
+	code, stdout, _ = runCmd(t, "1-2 x\n", "match", "-p", "{p}-{p} {line}", "-format", "csv")
+	assert.Equal(t, exitOK, code)
+	assert.Equal(t, `line,pattern,param:p,param:p#2,param:line
+1,{p}-{p} {line},1,2,x
+`, stdout)
+}
+
+func TestMatch_Unmatched(t *testing.T) {
+	code, stdout, stderr := runCmd(t, matchTestInput, "match", "-f", writePatternsFile(t), "-unmatched", "print")
+	assert.Equal(t, exitOK, code)
+	assert.Contains(t, stdout, `{"line":2,"matched":false,"input":"something else"}`+"\n")
+	assert.Equal(t, "", stderr)
+
+	code, stdout, stderr = runCmd(t, matchTestInput, "match", "-f", writePatternsFile(t), "-unmatched", "stderr")
+	assert.Equal(t, exitOK, code)
+	assert.NotContains(t, stdout, "something else")
+	assert.Equal(t, "something else\n", stderr)
+}
+
+func TestMatch_Count(t *testing.T) {
+	code, _, stderr := runCmd(t, matchTestInput, "match", "-f", writePatternsFile(t), "-count")
+	assert.Equal(t, exitOK, code)
+	assert.Equal(t, `lines: 4
+matched: 3
+unmatched: 1
+pattern "login": 2
+pattern "logout": 1
+`, stderr)
+}
+
+func TestMatch_ExitCodes(t *testing.T) {
+	code, stdout, _ := runCmd(t, "bar\n", "match", "-p", "foo")
+	assert.Equal(t, exitNoMatches, code)
+	assert.Equal(t, "", stdout)
+
+	code, _, stderr := runCmd(t, "", "match")
+	assert.Equal(t, exitError, code)
+	assert.Equal(t, "strparam match: patterns are required (-p or -f)\n", stderr)
+
+	code, _, stderr = runCmd(t, "", "match", "-p", "{}")
+	assert.Equal(t, exitError, code)
+	assert.Contains(t, stderr, `strparam match: pattern "{}": failed parse: empty name of parameter`)
+
+	code, _, stderr = runCmd(t, "", "match", "-p", "a", "-format", "xml")
+	assert.Equal(t, exitError, code)
+	assert.Equal(t, "strparam match: unknown format \"xml\"\n", stderr)
+
+	code, _, _ = runCmd(t, "", "match", "-p", "a", "notexists.txt")
+	assert.Equal(t, exitError, code)
+
+	code, _, _ = runCmd(t, "")
+	assert.Equal(t, exitError, code)
+	code, _, _ = runCmd(t, "", "unknown")
+	assert.Equal(t, exitError, code)
+	code, _, _ = runCmd(t, "", "match", "-h")
+	assert.Equal(t, exitOK, code)
+}
+
+func TestMatch_Files(t *testing.T) {
+	dir, err := ioutil.TempDir("", "strparam")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "input.txt")
+	require.NoError(t, ioutil.WriteFile(path, []byte("foo1\r\nfoo2"), 0644))
+
+	code, stdout, _ := runCmd(t, "foo3\n", "match", "-p", "foo{n}", path, "-")
+	assert.Equal(t, exitOK, code)
+	assert.Equal(t, `{"line":1,"matched":true,"pattern":"foo{n}","params":[{"name":"n","value":"1"}]}
+{"line":2,"matched":true,"pattern":"foo{n}","params":[{"name":"n","value":"2"}]}
+{"line":3,"matched":true,"pattern":"foo{n}","params":[{"name":"n","value":"3"}]}
+`, stdout)
+}
+
+func TestTest(t *testing.T) {
+	code, stdout, stderr := runCmd(t, "", "test", "../../strparamtest/testdata/login.yaml")
+	assert.Equal(t, exitOK, code)
+	assert.Empty(t, stderr)
+	assert.Equal(t, "../../strparamtest/testdata/login.yaml\n6 examples, 6 passed, 0 failed\n", stdout)
+
+	code, stdout, _ = runCmd(t, "", "test", "../../strparamtest/testdata/failed.yaml")
+	assert.Equal(t, exitTestFailed, code)
+	assert.Contains(t, stdout, "4 examples, 0 passed, 4 failed\n")
+
+	code, _, stderr = runCmd(t, "", "test")
+	assert.Equal(t, exitError, code)
+	assert.Equal(t, "strparam test: spec file is required\n", stderr)
+
+	code, _, _ = runCmd(t, "", "test", "not-exists.yaml")
+	assert.Equal(t, exitError, code)
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/gebv/strparam/strparamtest"
+)
+
+// runTest runs the command test, returns exit code.
+func runTest(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	verbose := fs.Bool("v", false, "print all examples (not only failed)")
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: strparam test [flags] spec.yaml ...")
+		fmt.Fprintln(stderr, "\nChecks examples of the test-spec files of patterns (see package strparamtest).")
+		fmt.Fprintln(stderr, "Exit codes: 0 if all examples are passed, 1 if some examples are failed, 2 if error.")
+		fmt.Fprintln(stderr, "\nFlags:")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return exitOK
+		}
+		return exitError
+	}
+	if fs.NArg() == 0 {
+		fmt.Fprintln(stderr, "strparam test: spec file is required")
+		return exitError
+	}
+
+	code := exitOK
+	for _, path := range fs.Args() {
+		spec, err := strparamtest.LoadSpec(path)
+		if err != nil {
+			fmt.Fprintf(stderr, "strparam test: %v\n", err)
+			return exitError
+		}
+		report, err := spec.Run()
+		if err != nil {
+			fmt.Fprintf(stderr, "strparam test: %s: %v\n", path, err)
+			return exitError
+		}
+
+		if *verbose {
+			for _, res := range report.Results {
+				if res.Passed {
+					fmt.Fprintf(stdout, "ok   %s\n", res.Name())
+				}
+			}
+		}
+		fmt.Fprintf(stdout, "%s\n%s", path, report)
+		if len(report.Failed()) > 0 {
+			code = exitTestFailed
+		}
+	}
+	return code
+}
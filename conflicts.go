@@ -0,0 +1,306 @@
+package strparam
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// ConflictKind kind of conflict of patterns.
+type ConflictKind int
+
+const (
+	// Overlap two patterns can match the same input.
+	Overlap ConflictKind = iota + 1
+	// Shadowed pattern is never returned by Find (other patterns win on its inputs).
+	Shadowed
+)
+
+// String returns human-readable format of kind of conflict.
+func (k ConflictKind) String() string {
+	switch k {
+	case Overlap:
+		return "overlap"
+	case Shadowed:
+		return "shadowed"
+	}
+	return fmt.Sprintf("ConflictKind(%d)", k)
+}
+
+// Conflict describes the conflict of patterns with the sample input demonstrating it.
+type Conflict struct {
+	Kind ConflictKind
+	// Pattern the first pattern of pair (or the shadowed pattern).
+	Pattern *Pattern
+	// Other the second pattern of pair (nil for shadowed pattern).
+	Other *Pattern
+	// Sample input matched (by Lookup) to both patterns of pair (or to shadowed pattern).
+	Sample string
+	// Winner pattern returned by Find for the sample (nil if not found).
+	Winner *Pattern
+}
+
+// String returns human-readable description of conflict.
+func (c Conflict) String() string {
+	winner := "no pattern is found"
+	if c.Winner != nil {
+		winner = "wins " + describePattern(c.Winner)
+	}
+	if c.Kind == Shadowed {
+		return fmt.Sprintf("pattern %s never matches: on input %q %s", describePattern(c.Pattern), c.Sample, winner)
+	}
+	return fmt.Sprintf("patterns %s and %s can match the same input %q (%s)",
+		describePattern(c.Pattern), describePattern(c.Other), c.Sample, winner)
+}
+
+func describePattern(p *Pattern) string {
+	if name := p.Name(); name != "" {
+		return fmt.Sprintf("%q (%s)", name, p.Source())
+	}
+	return fmt.Sprintf("%q", p.Source())
+}
+
+// Conflicts returns the conflicts of patterns of storage:
+// - pairs of patterns that can match the same input (eg /{a} and /{b}, /foo and /{x})
+// - patterns shadowed by others so they are never returned by Find
+//
+// Each conflict has the sample input (checked by Lookup and Find). Samples are searched among
+// the shortest inputs of patterns, so some conflicts of patterns with repeated constants may be missed.
+//
+// Can be used as a check of patterns on startup.
+func (r *Store) Conflicts() []Conflict {
+	patterns := r.Patterns()
+	// the registered pattern returned by Find
+	winner := func(in string) *Pattern {
+		match, _ := r.Match(in)
+		return match.Pattern
+	}
+
+	var res []Conflict
+	// samples of patterns matched by Lookup
+	samples := make([][]string, len(patterns))
+	for i, p := range patterns {
+		for _, sample := range patternSamples(p) {
+			if found, _ := p.Lookup(sample); found {
+				samples[i] = append(samples[i], sample)
+			}
+		}
+	}
+
+	for i, a := range patterns {
+		for j := i + 1; j < len(patterns); j++ {
+			b := patterns[j]
+			sample, ok := commonSample(a, b)
+			if !ok {
+				continue
+			}
+			samples[i] = append(samples[i], sample)
+			samples[j] = append(samples[j], sample)
+			res = append(res, Conflict{
+				Kind:    Overlap,
+				Pattern: a,
+				Other:   b,
+				Sample:  sample,
+				Winner:  winner(sample),
+			})
+		}
+	}
+
+	for i, p := range patterns {
+		if len(samples[i]) == 0 {
+			continue
+		}
+		shadowed := true
+		for _, sample := range samples[i] {
+			if winner(sample) == p {
+				shadowed = false
+				break
+			}
+		}
+		if shadowed {
+			res = append(res, Conflict{
+				Kind:    Shadowed,
+				Pattern: p,
+				Sample:  samples[i][0],
+				Winner:  winner(samples[i][0]),
+			})
+		}
+	}
+
+	return res
+}
+
+// sampleItem item of pattern for search of samples: a rune of constant, any rune or any text.
+type sampleItem struct {
+	char rune
+	any  bool
+	star bool
+}
+
+// sampleItems returns items of the pattern, each parameter is any text
+// (at least one rune if nonEmpty).
+func sampleItems(p *Pattern, nonEmpty bool) []sampleItem {
+	var res []sampleItem
+	for _, t := range p.Tokens {
+		switch t.Mode {
+		case CONST, SEPARATOR:
+			for _, char := range t.Raw {
+				res = append(res, sampleItem{char: char})
+			}
+		case PARAMETER:
+			if nonEmpty {
+				res = append(res, sampleItem{any: true})
+			}
+			res = append(res, sampleItem{star: true})
+		}
+	}
+	return res
+}
+
+// patternSamples returns the shortest inputs of the pattern (with non-empty and empty values of parameters).
+func patternSamples(p *Pattern) []string {
+	var res []string
+	for _, nonEmpty := range []bool{true, false} {
+		sample := new(strings.Builder)
+		filler := fillerRune(p)
+		for _, item := range sampleItems(p, nonEmpty) {
+			switch {
+			case item.any:
+				sample.WriteRune(filler)
+			case !item.star:
+				sample.WriteRune(item.char)
+			}
+		}
+		res = append(res, sample.String())
+	}
+	return res
+}
+
+// fillerRune returns rune that is not contained in constants of patterns.
+func fillerRune(patterns ...*Pattern) rune {
+	for _, char := range "xyzXYZ0123456789_" {
+		used := false
+		for _, p := range patterns {
+			for _, t := range p.Tokens {
+				if (t.Mode == CONST || t.Mode == SEPARATOR) && strings.ContainsRune(t.Raw, char) {
+					used = true
+				}
+			}
+		}
+		if !used {
+			return char
+		}
+	}
+	return utf8.RuneError
+}
+
+// commonSample returns the input matched (by Lookup) to both patterns.
+//
+// Candidates are the shortest common inputs (with non-empty values of parameters and then with any values)
+// found by breadth-first search on the product of the patterns as automatons.
+func commonSample(a, b *Pattern) (string, bool) {
+	filler := fillerRune(a, b)
+	for _, nonEmpty := range []bool{true, false} {
+		sample, ok := shortestCommon(sampleItems(a, nonEmpty), sampleItems(b, nonEmpty), filler)
+		if !ok {
+			continue
+		}
+		foundA, _ := a.Lookup(sample)
+		foundB, _ := b.Lookup(sample)
+		if foundA && foundB {
+			return sample, true
+		}
+	}
+	return "", false
+}
+
+func shortestCommon(a, b []sampleItem, filler rune) (string, bool) {
+	type state struct{ i, j int }
+	type visit struct {
+		prev state
+		char rune
+		// no rune for moves over empty text
+		empty bool
+	}
+
+	start := state{}
+	visited := map[state]visit{start: {}}
+	// breadth-first search, moves over empty text are added to the current level
+	queue := []state{start}
+	for len(queue) > 0 {
+		cur := queue[0]
+		queue = queue[1:]
+
+		if cur.i == len(a) && cur.j == len(b) {
+			var runes []rune
+			for cur != start {
+				v := visited[cur]
+				if !v.empty {
+					runes = append(runes, v.char)
+				}
+				cur = v.prev
+			}
+			for i, j := 0, len(runes)-1; i < j; i, j = i+1, j-1 {
+				runes[i], runes[j] = runes[j], runes[i]
+			}
+			return string(runes), true
+		}
+
+		// moves over empty text (skip of any text)
+		var empty []state
+		if cur.i < len(a) && a[cur.i].star {
+			empty = append(empty, state{cur.i + 1, cur.j})
+		}
+		if cur.j < len(b) && b[cur.j].star {
+			empty = append(empty, state{cur.i, cur.j + 1})
+		}
+		for _, next := range empty {
+			if _, exists := visited[next]; !exists {
+				visited[next] = visit{prev: cur, empty: true}
+				queue = append([]state{next}, queue...)
+			}
+		}
+
+		if cur.i >= len(a) || cur.j >= len(b) {
+			continue
+		}
+		for _, char := range candidateRunes(a[cur.i], b[cur.j], filler) {
+			nextI, okA := stepItem(a, cur.i, char)
+			nextJ, okB := stepItem(b, cur.j, char)
+			if !okA || !okB {
+				continue
+			}
+			next := state{nextI, nextJ}
+			if _, exists := visited[next]; !exists {
+				visited[next] = visit{prev: cur, char: char}
+				queue = append(queue, next)
+			}
+		}
+	}
+	return "", false
+}
+
+func candidateRunes(a, b sampleItem, filler rune) []rune {
+	var res []rune
+	if !a.any && !a.star {
+		res = append(res, a.char)
+	}
+	if !b.any && !b.star {
+		res = append(res, b.char)
+	}
+	if len(res) == 0 {
+		res = append(res, filler)
+	}
+	return res
+}
+
+// stepItem returns position after the rune at the item (any text stays at the same position).
+func stepItem(items []sampleItem, i int, char rune) (int, bool) {
+	switch item := items[i]; {
+	case item.star:
+		return i, true
+	case item.any || item.char == char:
+		return i + 1, true
+	}
+	return 0, false
+}
@@ -0,0 +1,88 @@
+package strparam
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStore_Conflicts(t *testing.T) {
+	tests := []struct {
+		name     string
+		patterns [][2]string
+		want     []string
+	}{
+		{
+			"no conflicts",
+			[][2]string{{"a", "/foo/{id}"}, {"b", "/bar/{id}"}, {"c", "/foo"}},
+			nil,
+		},
+		{
+			"same patterns with different names of parameters",
+			[][2]string{{"a", "/{a}"}, {"b", "/{b}"}},
+			[]string{
+				// NOTE: "b" wins on empty value of parameter
+				`patterns "a" (/{a}) and "b" (/{b}) can match the same input "/x" (wins "a" (/{a}))`,
+			},
+		},
+		{
+			"constant and parameter",
+			[][2]string{{"static", "/foo"}, {"param", "/{x}"}},
+			[]string{
+				`patterns "static" (/foo) and "param" (/{x}) can match the same input "/foo" (wins "static" (/foo))`,
+			},
+		},
+		{
+			"same pattern with different names",
+			[][2]string{{"a", "user={id}"}, {"b", "user={id}"}},
+			[]string{
+				`patterns "a" (user={id}) and "b" (user={id}) can match the same input "user=x" (wins "a" (user={id}))`,
+				`pattern "b" (user={id}) never matches: on input "user=x" wins "a" (user={id})`,
+			},
+		},
+		{
+			"overlapped params",
+			[][2]string{{"a", "{p1}.log"}, {"b", "app.{ext}"}},
+			[]string{
+				`patterns "b" (app.{ext}) and "a" ({p1}.log) can match the same input "app.log" (wins "b" (app.{ext}))`,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewStore()
+			for _, p := range tt.patterns {
+				_, err := r.AddNamed(p[0], p[1])
+				require.NoError(t, err)
+			}
+			var got []string
+			for _, conflict := range r.Conflicts() {
+				got = append(got, conflict.String())
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestStore_Conflicts_Fields(t *testing.T) {
+	r := NewStore()
+	_, err := r.AddNamed("a", "/{id}")
+	require.NoError(t, err)
+	_, err = r.AddNamed("b", "/{id}")
+	require.NoError(t, err)
+
+	conflicts := r.Conflicts()
+	require.Len(t, conflicts, 2)
+	assert.Equal(t, Overlap, conflicts[0].Kind)
+	assert.Equal(t, "a", conflicts[0].Pattern.Name())
+	assert.Equal(t, "b", conflicts[0].Other.Name())
+	assert.Equal(t, "/x", conflicts[0].Sample)
+	assert.Equal(t, conflicts[0].Pattern, conflicts[0].Winner)
+
+	assert.Equal(t, Shadowed, conflicts[1].Kind)
+	assert.Equal(t, "b", conflicts[1].Pattern.Name())
+	assert.Nil(t, conflicts[1].Other)
+	assert.Equal(t, "a", conflicts[1].Winner.Name())
+	assert.Equal(t, "shadowed", conflicts[1].Kind.String())
+}
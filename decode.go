@@ -0,0 +1,233 @@
+package strparam
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// TagName the name of struct tag for mapping the parameters to fields of struct.
+const TagName = "strparam"
+
+// LookupStruct same as Lookup but the found parameters are decoded into dst (see Params.Decode).
+//
+// Returns false (without error) if input string does not match to schema.
+func (s *Pattern) LookupStruct(in string, dst interface{}) (bool, error) {
+	found, params := s.Lookup(in)
+	if !found {
+		return false, nil
+	}
+	return true, params.Decode(dst)
+}
+
+// Decode sets values of parameters to fields of struct by tag `strparam:"name"`.
+// The dst must be a non-nil pointer to struct.
+//
+// Supported types of fields (and pointers to them):
+// string, bool, int*, uint*, float*, time.Duration (time.ParseDuration),
+// time.Time (RFC 3339) and any type that implements encoding.TextUnmarshaler.
+//
+// Fields without tag (or with tag "-") and parameters without field are skipped.
+// Conversion errors are collected for all fields and returned as DecodeErrors.
+func (p Params) Decode(dst interface{}) error {
+	rv := reflect.ValueOf(dst)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return errors.New("dst must be a non-nil pointer to struct")
+	}
+	rv = rv.Elem()
+	if rv.Kind() != reflect.Struct {
+		return fmt.Errorf("dst must be a non-nil pointer to struct, got pointer to %s", rv.Kind())
+	}
+
+	plan, err := getDecodePlan(rv.Type())
+	if err != nil {
+		return err
+	}
+
+	var errs DecodeErrors
+	for _, param := range p {
+		field, exists := plan[param.Name]
+		if !exists {
+			continue
+		}
+		if err := field.set(rv.FieldByIndex(field.index), param.Value); err != nil {
+			errs = append(errs, &FieldError{
+				Field: field.name,
+				Param: param.Name,
+				Value: param.Value,
+				Err:   err,
+			})
+		}
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// FieldError error of conversion value of parameter to field of struct.
+type FieldError struct {
+	Field string
+	Param string
+	Value string
+	Err   error
+}
+
+func (e *FieldError) Error() string {
+	return fmt.Sprintf("field %q (parameter %q, value %q): %v", e.Field, e.Param, e.Value, e.Err)
+}
+
+// Unwrap returns the original error of conversion.
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+// DecodeErrors list of errors of conversion (per field).
+type DecodeErrors []*FieldError
+
+func (e DecodeErrors) Error() string {
+	msgs := make([]string, 0, len(e))
+	for _, err := range e {
+		msgs = append(msgs, err.Error())
+	}
+	return "failed decode params: " + strings.Join(msgs, "; ")
+}
+
+// decodeField the mapping of parameter to field of struct.
+type decodeField struct {
+	name  string
+	index []int
+	set   func(v reflect.Value, in string) error
+}
+
+// decodePlan the mapping by parameter name.
+type decodePlan map[string]decodeField
+
+// cache of plans by type of struct
+var decodePlans sync.Map
+
+func getDecodePlan(t reflect.Type) (decodePlan, error) {
+	if cached, ok := decodePlans.Load(t); ok {
+		return cached.(decodePlan), nil
+	}
+
+	plan := decodePlan{}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get(TagName)
+		if tag == "" || tag == "-" {
+			continue
+		}
+		if field.PkgPath != "" {
+			return nil, fmt.Errorf("field %q with tag %q is not exported", field.Name, tag)
+		}
+		if _, exists := plan[tag]; exists {
+			return nil, fmt.Errorf("duplicate tag %q for field %q", tag, field.Name)
+		}
+		setter, err := newFieldSetter(field.Type)
+		if err != nil {
+			return nil, fmt.Errorf("field %q: %v", field.Name, err)
+		}
+		plan[tag] = decodeField{
+			name:  field.Name,
+			index: field.Index,
+			set:   setter,
+		}
+	}
+
+	decodePlans.Store(t, plan)
+	return plan, nil
+}
+
+var (
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	durationType        = reflect.TypeOf(time.Duration(0))
+)
+
+// newFieldSetter returns function of conversion for the type of field.
+//
+// nolint: gocyclo
+func newFieldSetter(t reflect.Type) (func(v reflect.Value, in string) error, error) {
+	if t.Kind() == reflect.Ptr {
+		elemSetter, err := newFieldSetter(t.Elem())
+		if err != nil {
+			return nil, err
+		}
+		return func(v reflect.Value, in string) error {
+			if v.IsNil() {
+				v.Set(reflect.New(t.Elem()))
+			}
+			return elemSetter(v.Elem(), in)
+		}, nil
+	}
+
+	if reflect.PtrTo(t).Implements(textUnmarshalerType) {
+		// NOTE: time.Time implements encoding.TextUnmarshaler (RFC 3339)
+		return func(v reflect.Value, in string) error {
+			return v.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(in))
+		}, nil
+	}
+
+	if t == durationType {
+		return func(v reflect.Value, in string) error {
+			d, err := time.ParseDuration(in)
+			if err != nil {
+				return err
+			}
+			v.SetInt(int64(d))
+			return nil
+		}, nil
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return func(v reflect.Value, in string) error {
+			v.SetString(in)
+			return nil
+		}, nil
+	case reflect.Bool:
+		return func(v reflect.Value, in string) error {
+			b, err := strconv.ParseBool(in)
+			if err != nil {
+				return err
+			}
+			v.SetBool(b)
+			return nil
+		}, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return func(v reflect.Value, in string) error {
+			n, err := strconv.ParseInt(in, 10, t.Bits())
+			if err != nil {
+				return err
+			}
+			v.SetInt(n)
+			return nil
+		}, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return func(v reflect.Value, in string) error {
+			n, err := strconv.ParseUint(in, 10, t.Bits())
+			if err != nil {
+				return err
+			}
+			v.SetUint(n)
+			return nil
+		}, nil
+	case reflect.Float32, reflect.Float64:
+		return func(v reflect.Value, in string) error {
+			n, err := strconv.ParseFloat(in, t.Bits())
+			if err != nil {
+				return err
+			}
+			v.SetFloat(n)
+			return nil
+		}, nil
+	}
+
+	return nil, fmt.Errorf("not supported type %s", t)
+}
@@ -0,0 +1,147 @@
+package strparam
+
+import (
+	"errors"
+	"net"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type decodeTestStruct struct {
+	Name     string        `strparam:"name"`
+	Age      int           `strparam:"age"`
+	Small    int8          `strparam:"small"`
+	Count    uint          `strparam:"count"`
+	Ratio    float64       `strparam:"ratio"`
+	Active   bool          `strparam:"active"`
+	Timeout  time.Duration `strparam:"timeout"`
+	At       time.Time     `strparam:"at"`
+	IP       net.IP        `strparam:"ip"`
+	Optional *int          `strparam:"optional"`
+	Skipped  string        `strparam:"-"`
+	NoTag    string
+}
+
+func TestParams_Decode(t *testing.T) {
+	params := Params{
+		{"name", "日本語"},
+		{"age", "42"},
+		{"small", "-8"},
+		{"count", "7"},
+		{"ratio", "0.5"},
+		{"active", "true"},
+		{"timeout", "1m30s"},
+		{"at", "2020-05-01T10:00:00Z"},
+		{"ip", "127.0.0.1"},
+		{"optional", "3"},
+		{"unknown", "value"},
+	}
+
+	var got decodeTestStruct
+	require.NoError(t, params.Decode(&got))
+
+	optional := 3
+	assert.EqualValues(t, decodeTestStruct{
+		Name:     "日本語",
+		Age:      42,
+		Small:    -8,
+		Count:    7,
+		Ratio:    0.5,
+		Active:   true,
+		Timeout:  90 * time.Second,
+		At:       time.Date(2020, 5, 1, 10, 0, 0, 0, time.UTC),
+		IP:       net.ParseIP("127.0.0.1"),
+		Optional: &optional,
+	}, got)
+}
+
+func TestParams_Decode_Errors(t *testing.T) {
+	params := Params{
+		{"name", "foo"},
+		{"age", "abc"},
+		{"small", "1000"},
+		{"active", "yes"},
+	}
+
+	var got decodeTestStruct
+	err := params.Decode(&got)
+	require.Error(t, err)
+	assert.Equal(t, "foo", got.Name)
+
+	var errs DecodeErrors
+	require.True(t, errors.As(err, &errs))
+	require.Len(t, errs, 3)
+	assert.Equal(t, "Age", errs[0].Field)
+	assert.Equal(t, "age", errs[0].Param)
+	assert.Equal(t, "abc", errs[0].Value)
+	assert.True(t, errors.Is(errs[0], strconv.ErrSyntax))
+	assert.Equal(t, "Small", errs[1].Field)
+	assert.True(t, errors.Is(errs[1], strconv.ErrRange))
+	assert.Equal(t, "Active", errs[2].Field)
+	assert.Contains(t, err.Error(), `field "Age" (parameter "age", value "abc")`)
+}
+
+func TestParams_Decode_InvalidDst(t *testing.T) {
+	var got decodeTestStruct
+	assert.EqualError(t, Params{}.Decode(got), "dst must be a non-nil pointer to struct")
+	assert.EqualError(t, Params{}.Decode((*decodeTestStruct)(nil)), "dst must be a non-nil pointer to struct")
+	var n int
+	assert.EqualError(t, Params{}.Decode(&n), "dst must be a non-nil pointer to struct, got pointer to int")
+
+	var unsupported struct {
+		Ch chan int `strparam:"ch"`
+	}
+	assert.EqualError(t, Params{}.Decode(&unsupported), `field "Ch": not supported type chan int`)
+
+	var duplicate struct {
+		A string `strparam:"a"`
+		B string `strparam:"a"`
+	}
+	assert.EqualError(t, Params{}.Decode(&duplicate), `duplicate tag "a" for field "B"`)
+}
+
+func TestPattern_LookupStruct(t *testing.T) {
+	p, err := Parse("user {name} logged in from {ip} port {port}")
+	require.NoError(t, err)
+
+	var got struct {
+		Name string `strparam:"name"`
+		IP   net.IP `strparam:"ip"`
+		Port uint16 `strparam:"port"`
+	}
+
+	found, err := p.LookupStruct("user bob logged in from 10.0.0.1 port 22", &got)
+	require.NoError(t, err)
+	require.True(t, found)
+	assert.Equal(t, "bob", got.Name)
+	assert.Equal(t, "10.0.0.1", got.IP.String())
+	assert.EqualValues(t, 22, got.Port)
+
+	found, err = p.LookupStruct("user bob logged out", &got)
+	require.NoError(t, err)
+	require.False(t, found)
+
+	found, err = p.LookupStruct("user bob logged in from 10.0.0.1 port 100000", &got)
+	require.True(t, found)
+	require.Error(t, err)
+}
+
+func BenchmarkPattern_LookupStruct(b *testing.B) {
+	p, _ := Parse("user {name} logged in from {ip} port {port}")
+	in := "user bob logged in from 10.0.0.1 port 22"
+	var dst struct {
+		Name string `strparam:"name"`
+		IP   string `strparam:"ip"`
+		Port int    `strparam:"port"`
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		p.LookupStruct(in, &dst)
+	}
+}
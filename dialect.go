@@ -0,0 +1,250 @@
+package strparam
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// Dialect parses the named pattern written in some syntax into tokens.
+type Dialect func(name, exp string) (*Pattern, error)
+
+var (
+	// DefaultDialect syntax of strparam: foo={p1}.
+	DefaultDialect Dialect = ParseWithName
+
+	// ColonDialect syntax of httprouter and gin: /users/:id/files/*path.
+	//
+	// Parameter :name takes the rest of the segment (non-empty, without slash), catch-all *name takes the tail
+	// and should be at the end.
+	ColonDialect Dialect = parseColon
+
+	// MuxDialect syntax of gorilla/mux: /users/{id}, /users/{id:[^/]+}.
+	//
+	// Regexp constraints are not supported except of the default [^/]+ (same as without constraint,
+	// non-empty value without slash) and .* or .+ (any value).
+	MuxDialect Dialect = parseMux
+
+	// OpenAPIDialect syntax of OpenAPI path templates: /users/{userId}/files/{name}.{ext}.
+	//
+	// Path starts with slash, name of parameter is not empty and does not contain slash or braces.
+	// Value of parameter is a non-empty segment of path (without slash).
+	OpenAPIDialect Dialect = parseOpenAPI
+
+	// GlobDialect syntax of shell globs: *.log, /var/log/*/error.log.
+	//
+	// Each * is a parameter named by its number (p1, p2, ...), \ escapes next character.
+	// Single character ? and classes [...] are not supported by tokens of patterns.
+	// NOTE: unlike of shell * matches slashes.
+	GlobDialect Dialect = parseGlob
+)
+
+// patternBuilder helper for building tokens of pattern by dialects.
+type patternBuilder struct {
+	tokens Tokens
+	names  map[string]bool
+}
+
+func newPatternBuilder() *patternBuilder {
+	return &patternBuilder{
+		tokens: Tokens{StartToken},
+		names:  map[string]bool{},
+	}
+}
+
+func (b *patternBuilder) addConst(raw string) {
+	if raw == "" {
+		return
+	}
+	if last := &b.tokens[len(b.tokens)-1]; last.Mode == CONST {
+		*last = ConstToken(last.Raw + raw)
+		return
+	}
+	b.tokens = append(b.tokens, ConstToken(raw))
+}
+
+// addParam appends the parameter, the value of segment parameter is limited by segment of path.
+func (b *patternBuilder) addParam(name string, segment bool, pos int) error {
+	if name == "" {
+		return fmt.Errorf("empty name of parameter, pos %d", pos)
+	}
+	if b.tokens[len(b.tokens)-1].Mode == PARAMETER {
+		return fmt.Errorf("should be a pattern between the parameters, pos %d", pos)
+	}
+	if b.names[name] {
+		return fmt.Errorf("duplicate name of parameter %q, pos %d", name, pos)
+	}
+	b.names[name] = true
+	if segment {
+		b.tokens = append(b.tokens, SegmentParameterToken(name))
+	} else {
+		b.tokens = append(b.tokens, ParameterToken(name))
+	}
+	return nil
+}
+
+func (b *patternBuilder) pattern(name string) (*Pattern, error) {
+	if len(b.tokens) == 1 {
+		return nil, errors.New("expression should not is empty")
+	}
+	return &Pattern{
+		Tokens:    append(b.tokens, NamedEndToken(name)),
+		NumParams: len(b.names),
+	}, nil
+}
+
+func parseColon(name, exp string) (*Pattern, error) {
+	b := newPatternBuilder()
+	for i := 0; i < len(exp); {
+		switch exp[i] {
+		case ':', '*':
+			end := strings.IndexByte(exp[i:], '/')
+			if end < 0 {
+				end = len(exp)
+			} else {
+				end += i
+			}
+			if exp[i] == '*' && end != len(exp) {
+				return nil, fmt.Errorf("catch-all parameter should be at the end, pos %d", i)
+			}
+			if strings.ContainsAny(exp[i+1:end], ":*") {
+				return nil, fmt.Errorf("only one parameter is allowed per segment, pos %d", i)
+			}
+			if err := b.addParam(exp[i+1:end], exp[i] == ':', i); err != nil {
+				return nil, err
+			}
+			i = end
+		default:
+			next := strings.IndexAny(exp[i:], ":*")
+			if next < 0 {
+				next = len(exp)
+			} else {
+				next += i
+			}
+			b.addConst(exp[i:next])
+			i = next
+		}
+	}
+	return b.pattern(name)
+}
+
+func parseMux(name, exp string) (*Pattern, error) {
+	b := newPatternBuilder()
+	for i := 0; i < len(exp); {
+		if exp[i] != '{' {
+			next := strings.IndexByte(exp[i:], '{')
+			if next < 0 {
+				next = len(exp)
+			} else {
+				next += i
+			}
+			if strings.IndexByte(exp[i:next], '}') >= 0 {
+				return nil, fmt.Errorf("unbalanced braces, pos %d", i+strings.IndexByte(exp[i:next], '}'))
+			}
+			b.addConst(exp[i:next])
+			i = next
+			continue
+		}
+
+		// the end of variable with balanced braces of regexp
+		end, level := -1, 0
+		for j := i; j < len(exp) && end < 0; j++ {
+			switch exp[j] {
+			case '{':
+				level++
+			case '}':
+				level--
+				if level == 0 {
+					end = j
+				}
+			}
+		}
+		if end < 0 {
+			return nil, fmt.Errorf("parameter was not closed, pos %d", i)
+		}
+
+		paramName, constraint := exp[i+1:end], ""
+		if sep := strings.IndexByte(paramName, ':'); sep >= 0 {
+			paramName, constraint = paramName[:sep], paramName[sep+1:]
+		}
+		var segment bool
+		switch constraint {
+		case "", "[^/]+":
+			segment = true
+		case ".*", ".+":
+		default:
+			return nil, fmt.Errorf("not supported constraint %q of parameter %q, pos %d", constraint, paramName, i)
+		}
+		if err := b.addParam(paramName, segment, i); err != nil {
+			return nil, err
+		}
+		i = end + 1
+	}
+	return b.pattern(name)
+}
+
+func parseOpenAPI(name, exp string) (*Pattern, error) {
+	if !strings.HasPrefix(exp, "/") {
+		return nil, errors.New("path should start with a slash")
+	}
+	if pos := strings.IndexAny(exp, "?#"); pos >= 0 {
+		return nil, fmt.Errorf("query and fragment are not allowed in path, pos %d", pos)
+	}
+
+	b := newPatternBuilder()
+	for i := 0; i < len(exp); {
+		switch exp[i] {
+		case '{':
+			end := strings.IndexAny(exp[i+1:], "{}/")
+			if end < 0 || exp[i+1+end] != '}' {
+				return nil, fmt.Errorf("parameter was not closed, pos %d", i)
+			}
+			end += i + 1
+			if err := b.addParam(exp[i+1:end], true, i); err != nil {
+				return nil, err
+			}
+			i = end + 1
+		case '}':
+			return nil, fmt.Errorf("unbalanced braces, pos %d", i)
+		default:
+			next := strings.IndexAny(exp[i:], "{}")
+			if next < 0 {
+				next = len(exp)
+			} else {
+				next += i
+			}
+			b.addConst(exp[i:next])
+			i = next
+		}
+	}
+	return b.pattern(name)
+}
+
+func parseGlob(name, exp string) (*Pattern, error) {
+	b := newPatternBuilder()
+	for i, w := 0, 0; i < len(exp); i += w {
+		var char rune
+		char, w = utf8.DecodeRuneInString(exp[i:])
+		switch char {
+		case '*':
+			if err := b.addParam(fmt.Sprintf("p%d", len(b.names)+1), false, i); err != nil {
+				return nil, err
+			}
+		case '?':
+			return nil, fmt.Errorf("not supported single character wildcard '?', pos %d", i)
+		case '[':
+			return nil, fmt.Errorf("not supported character class '[', pos %d", i)
+		case '\\':
+			if i+w >= len(exp) {
+				return nil, fmt.Errorf("nothing to escape, pos %d", i)
+			}
+			_, size := utf8.DecodeRuneInString(exp[i+w:])
+			b.addConst(exp[i+w : i+w+size])
+			w += size
+		default:
+			b.addConst(exp[i : i+w])
+		}
+	}
+	return b.pattern(name)
+}
@@ -0,0 +1,144 @@
+package strparam
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDialects(t *testing.T) {
+	tests := []struct {
+		dialect Dialect
+		exp     string
+		want    string
+		wantErr string
+	}{
+		{DefaultDialect, "/users/{id}", `START->Const("/users/", len=7)->Param("{id}")->END("name")`, ""},
+
+		{ColonDialect, "/users/:id/files/*path", `START->Const("/users/", len=7)->Param("{id}", segment)->Const("/files/", len=7)->Param("{path}")->END("name")`, ""},
+		{ColonDialect, "/users/:id", `START->Const("/users/", len=7)->Param("{id}", segment)->END("name")`, ""},
+		{ColonDialect, "/files/*path/info", "", "catch-all parameter should be at the end, pos 7"},
+		{ColonDialect, "/users/:", "", "empty name of parameter, pos 7"},
+		{ColonDialect, "/users/:id:name", "", "only one parameter is allowed per segment, pos 7"},
+		{ColonDialect, "/:id/:id", "", `duplicate name of parameter "id", pos 5`},
+		{ColonDialect, "", "", "expression should not is empty"},
+
+		{MuxDialect, "/users/{id:[^/]+}/{name}", `START->Const("/users/", len=7)->Param("{id}", segment)->Const("/", len=1)->Param("{name}", segment)->END("name")`, ""},
+		{MuxDialect, "/users/{id}", `START->Const("/users/", len=7)->Param("{id}", segment)->END("name")`, ""},
+		{MuxDialect, "/files/{path:.*}", `START->Const("/files/", len=7)->Param("{path}")->END("name")`, ""},
+		{MuxDialect, "/users/{id:[0-9]{1,3}}", "", `not supported constraint "[0-9]{1,3}" of parameter "id", pos 7`},
+		{MuxDialect, "/users/{id", "", "parameter was not closed, pos 7"},
+		{MuxDialect, "/users/}", "", "unbalanced braces, pos 7"},
+		{MuxDialect, "/{a}{b}", "", "should be a pattern between the parameters, pos 4"},
+
+		{OpenAPIDialect, "/files/{name}.{ext}", `START->Const("/files/", len=7)->Param("{name}", segment)->Const(".", len=1)->Param("{ext}", segment)->END("name")`, ""},
+		{OpenAPIDialect, "files/{name}", "", "path should start with a slash"},
+		{OpenAPIDialect, "/files?name={name}", "", "query and fragment are not allowed in path, pos 6"},
+		{OpenAPIDialect, "/files/{a/b}", "", "parameter was not closed, pos 7"},
+		{OpenAPIDialect, "/files/{}", "", "empty name of parameter, pos 7"},
+		{OpenAPIDialect, "/files/a}", "", "unbalanced braces, pos 8"},
+
+		{GlobDialect, "*.log", `START->Param("{p1}")->Const(".log", len=4)->END("name")`, ""},
+		{GlobDialect, "/var/log/*/error\\*.*", `START->Const("/var/log/", len=9)->Param("{p1}")->Const("/error*.", len=8)->Param("{p2}")->END("name")`, ""},
+		{GlobDialect, "file?.log", "", "not supported single character wildcard '?', pos 4"},
+		{GlobDialect, "file[0-9].log", "", "not supported character class '[', pos 4"},
+		{GlobDialect, "**", "", "should be a pattern between the parameters, pos 1"},
+		{GlobDialect, "a\\", "", "nothing to escape, pos 1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.exp, func(t *testing.T) {
+			got, err := tt.dialect("name", tt.exp)
+			if tt.wantErr != "" {
+				require.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got.String())
+		})
+	}
+}
+
+func TestNewStoreWithDialect(t *testing.T) {
+	r := NewStoreWithDialect(ColonDialect)
+	_, err := r.AddNamed("user", "/users/:id")
+	require.NoError(t, err)
+	_, err = r.AddNamed("file", "/files/*path")
+	require.NoError(t, err)
+
+	found := r.Find("/files/a/b.txt")
+	require.NotNil(t, found)
+	assert.Equal(t, "file", found.Name())
+	ok, params := found.Lookup("/files/a/b.txt")
+	assert.True(t, ok)
+	assert.EqualValues(t, Params{{"path", "a/b.txt"}}, params)
+
+	_, err = r.Add("/users/:")
+	require.EqualError(t, err, "failed parse: empty name of parameter, pos 7")
+}
+
+func TestDialects_SegmentParameter(t *testing.T) {
+	tests := []struct {
+		dialect Dialect
+		exp     string
+	}{
+		{ColonDialect, "/users/:id"},
+		{MuxDialect, "/users/{id}"},
+		{MuxDialect, "/users/{id:[^/]+}"},
+		{OpenAPIDialect, "/users/{id}"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.exp, func(t *testing.T) {
+			p, err := tt.dialect("user", tt.exp)
+			require.NoError(t, err)
+
+			ok, params := p.Lookup("/users/a")
+			assert.True(t, ok)
+			assert.EqualValues(t, Params{{"id", "a"}}, params)
+
+			r := NewStore()
+			require.NoError(t, r.AddPattern(p))
+			require.NotNil(t, r.Find("/users/a"))
+
+			for _, in := range []string{"/users/", "/users/a/b"} {
+				ok, _ := p.Lookup(in)
+				assert.False(t, ok, in)
+				assert.Nil(t, r.Find(in), in)
+				_, found := r.Match(in)
+				assert.False(t, found, in)
+				assert.Empty(t, r.FindAll(in), in)
+				assert.Empty(t, p.FindAllIndex(in, -1), in)
+				ok, _, _ = p.MatchPrefix(in)
+				assert.False(t, ok, in)
+			}
+		})
+	}
+}
+
+func TestNewStoreWithDialect_SegmentAndCatchAll(t *testing.T) {
+	r := NewStoreWithDialect(ColonDialect)
+	_, err := r.AddNamed("user", "/users/:id")
+	require.NoError(t, err)
+	_, err = r.AddNamed("files", "/users/*path")
+	require.NoError(t, err)
+
+	tests := []struct {
+		in         string
+		wantName   string
+		wantParams Params
+	}{
+		{"/users/a", "user", Params{{"id", "a"}}},
+		{"/users/a/b", "files", Params{{"path", "a/b"}}},
+		{"/users/", "files", Params{{"path", ""}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			found := r.Find(tt.in)
+			require.NotNil(t, found)
+			assert.Equal(t, tt.wantName, found.Name())
+			ok, params := found.Lookup(tt.in)
+			assert.True(t, ok)
+			assert.EqualValues(t, tt.wantParams, params)
+		})
+	}
+}
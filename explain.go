@@ -0,0 +1,161 @@
+package strparam
+
+import (
+	"fmt"
+	"strings"
+)
+
+// StepResult result of the step of walk of tree (see Trace).
+type StepResult int
+
+const (
+	// StepMatched the node is matched, the walk goes deeper.
+	StepMatched StepResult = iota
+	// StepConstMismatch value of constant does not match to the input at the offset.
+	StepConstMismatch
+	// StepConstOutOfInput constant is longer than the rest of input.
+	StepConstOutOfInput
+	// StepConstNotAtEnd constant is the last of pattern, but it is not at the end of input.
+	StepConstNotAtEnd
+	// StepNextPrefixMismatch constant is matched, but no constant after it matches to the rest of input
+	// (and there are no parameters after it).
+	StepNextPrefixMismatch
+	// StepEndLeftover the end of pattern is reached, but the input is not consumed.
+	StepEndLeftover
+	// StepNextConstNotFound constant after parameter is not found in the rest of input.
+	StepNextConstNotFound
+	// StepParamUnterminated no constant after parameter is found in the rest of input.
+	StepParamUnterminated
+	// StepEmptyParamSkipped empty value of parameter is skipped because the next siblings are tried.
+	StepEmptyParamSkipped
+	// StepParamNotSegment value of parameter is not a segment of path (see SegmentParameterToken).
+	StepParamNotSegment
+)
+
+// String returns human-readable format of result of step.
+func (r StepResult) String() string {
+	switch r {
+	case StepMatched:
+		return "matched"
+	case StepConstMismatch:
+		return "constant does not match"
+	case StepConstOutOfInput:
+		return "constant is longer than the rest of input"
+	case StepConstNotAtEnd:
+		return "constant of the end of pattern is not at the end of input"
+	case StepNextPrefixMismatch:
+		return "no next constant matches the rest of input"
+	case StepEndLeftover:
+		return "end of pattern, but input is not consumed"
+	case StepNextConstNotFound:
+		return "constant after parameter is not found"
+	case StepParamUnterminated:
+		return "no constant after parameter is found"
+	case StepEmptyParamSkipped:
+		return "empty value of parameter is skipped"
+	case StepParamNotSegment:
+		return "value of parameter is not a segment of path"
+	}
+	return fmt.Sprintf("StepResult(%d)", r)
+}
+
+// TraceStep the visited node of tree.
+type TraceStep struct {
+	// Depth depth of node in the matched path
+	Depth int
+	// Token token of the node
+	Token Token
+	// Offset position in the input (for constant after parameter the position where it is found)
+	Offset int
+	Result StepResult
+	// Value value of parameter (for matched parameter)
+	Value string
+}
+
+// Trace describes the walk of tree by Find for the input: every visited node with the result of comparison.
+type Trace struct {
+	Input string
+	Steps []TraceStep
+	// Found the pattern returned by Find (nil if not found).
+	Found *Pattern
+	// Partial tokens of the deepest partial match (the full pattern if found).
+	Partial Tokens
+	// Consumed number of bytes of the input consumed by the partial match.
+	Consumed int
+	// Prioritized the found pattern has higher priority than the pattern found by the walk
+	// (see Store.AddWithPriority), the steps are of the walk.
+	Prioritized bool
+}
+
+// Explain returns the trace of Find for the input: why the pattern is matched or not matched.
+func (r *Store) Explain(in string) Trace {
+	state := r.load()
+	res := Trace{Input: in}
+	tokens := make([]Token, 0, state.maxSize)
+	numParams := 0
+
+	lookup(in, state.root, &tokens, &numParams, &res)
+
+	res.Partial = tokens
+	for _, t := range tokens {
+		switch t.Mode {
+		case CONST, SEPARATOR, PARAMETER_PARSED:
+			res.Consumed += t.Len
+		}
+	}
+	if len(tokens) > 2 && tokens[0].Mode == START && tokens[len(tokens)-1].Mode == END {
+		res.Found = &Pattern{Tokens: tokens, NumParams: numParams}
+	}
+	return res
+}
+
+// String returns the trace as text: visited nodes (indented by depth) and the result.
+func (t Trace) String() string {
+	res := new(strings.Builder)
+	fmt.Fprintf(res, "input %q (%d bytes)\n", t.Input, len(t.Input))
+	for _, step := range t.Steps {
+		fmt.Fprintf(res, "%s%s at %d: %s", strings.Repeat("  ", step.Depth), step.Token.String(), step.Offset, step.Result)
+		if step.Token.Mode == PARAMETER && step.Result == StepMatched {
+			fmt.Fprintf(res, " (value %q)", step.Value)
+		}
+		res.WriteString("\n")
+	}
+	if t.Found != nil && t.Prioritized {
+		fmt.Fprintf(res, "found by priority %s", t.Found.Tokens)
+		return res.String()
+	}
+	if t.Found != nil {
+		fmt.Fprintf(res, "found %s", t.Found.Tokens)
+		return res.String()
+	}
+	fmt.Fprintf(res, "not found, the deepest partial match %q (%d of %d bytes)", t.Input[:t.Consumed], t.Consumed, len(t.Input))
+	if len(t.Partial) > 0 {
+		fmt.Fprintf(res, ": %s", t.Partial)
+	}
+	return res.String()
+}
+
+// add records the step, returns the index of step (-1 if the trace is nil).
+func (t *Trace) add(depth int, token Token, offset int, result StepResult) int {
+	if t == nil {
+		return -1
+	}
+	t.Steps = append(t.Steps, TraceStep{Depth: depth, Token: token, Offset: offset, Result: result})
+	return len(t.Steps) - 1
+}
+
+// setPrioritized marks the found pattern as found by priority.
+func (t *Trace) setPrioritized() {
+	if t != nil {
+		t.Prioritized = true
+	}
+}
+
+// set sets result of the recorded step.
+func (t *Trace) set(step int, result StepResult, value string) {
+	if t == nil || step < 0 {
+		return
+	}
+	t.Steps[step].Result = result
+	t.Steps[step].Value = value
+}
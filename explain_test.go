@@ -0,0 +1,139 @@
+package strparam
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStore_Explain(t *testing.T) {
+	s := NewStore()
+	s.AddNamed("index", "/")
+	s.AddNamed("files", "/users/{id}/files")
+
+	trace := s.Explain("/users/1/x")
+	assert.Nil(t, trace.Found)
+	assert.Equal(t, 7, trace.Consumed)
+	assert.Equal(t, `input "/users/1/x" (10 bytes)
+START at 0: matched
+  Const("/users/", len=7) at 0: matched
+    Param("{id}") at 7: no constant after parameter is found
+      Const("/files", len=6) at 7: constant after parameter is not found
+not found, the deepest partial match "/users/" (7 of 10 bytes): START->Const("/users/", len=7)`, trace.String())
+
+	trace = s.Explain("/users/1/files")
+	require.NotNil(t, trace.Found)
+	assert.Equal(t, s.Find("/users/1/files").String(), trace.Found.String())
+	assert.Equal(t, 14, trace.Consumed)
+	assert.Equal(t, `input "/users/1/files" (14 bytes)
+START at 0: matched
+  Const("/users/", len=7) at 0: matched
+    Param("{id}") at 7: matched (value "1")
+      Const("/files", len=6) at 8: matched
+        END("files") at 14: matched
+found START->Const("/users/", len=7)->ParsedParam(id="1")->Const("/files", len=6)->END("files")`, trace.String())
+
+	// the pattern with higher priority among matched patterns
+	s.AddWithPriority("/users/{id}/{tail}", 1)
+	trace = s.Explain("/users/1/files")
+	require.NotNil(t, trace.Found)
+	assert.True(t, trace.Prioritized)
+	assert.Equal(t, s.Find("/users/1/files").String(), trace.Found.String())
+	assert.Contains(t, trace.String(), `found by priority START->Const("/users/", len=7)->ParsedParam(id="1")->Const("/", len=1)->ParsedParam(tail="files")->END`)
+
+	trace = NewStore().Explain("foo")
+	assert.Nil(t, trace.Found)
+	assert.Empty(t, trace.Steps)
+	assert.Equal(t, `input "foo" (3 bytes)
+not found, the deepest partial match "" (0 of 3 bytes)`, trace.String())
+}
+
+func TestStore_Explain_Results(t *testing.T) {
+	tests := []struct {
+		patterns []*Pattern
+		in       string
+		want     StepResult
+	}{
+		{mustParsePatterns("/foo"), "/bar", StepConstMismatch},
+		{mustParsePatterns("/foo/{id}"), "/foo", StepConstOutOfInput},
+		{mustParsePatterns("/foo"), "/foo/bar", StepConstNotAtEnd},
+		{
+			[]*Pattern{
+				{Tokens: Tokens{StartToken, ConstToken("/a"), ConstToken("/b"), EndToken}},
+				{Tokens: Tokens{StartToken, ConstToken("/a"), ConstToken("/c"), EndToken}},
+			},
+			"/a/d",
+			StepNextPrefixMismatch,
+		},
+		{mustParsePatterns("/{p}", "/{p}/x"), "/1/x/z", StepEndLeftover},
+		{mustParsePatterns("/{p}/x"), "/1/y", StepNextConstNotFound},
+		{mustParsePatterns("/{p}/x"), "/1/y", StepParamUnterminated},
+		{mustParsePatterns("/{a}", "/{b}"), "/", StepEmptyParamSkipped},
+		{
+			[]*Pattern{{Tokens: Tokens{StartToken, ConstToken("/"), SegmentParameterToken("id"), EndToken}, NumParams: 1}},
+			"/1/2",
+			StepParamNotSegment,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%s: %s", tt.want, tt.in), func(t *testing.T) {
+			s := NewStore()
+			for _, p := range tt.patterns {
+				require.NoError(t, s.AddPattern(p))
+			}
+			trace := s.Explain(tt.in)
+			var results []StepResult
+			for _, step := range trace.Steps {
+				results = append(results, step.Result)
+			}
+			assert.Contains(t, results, tt.want, trace.String())
+		})
+	}
+}
+
+// checks that Explain returns the same pattern as Find
+func TestStore_Explain_SameAsFind(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	segments := []string{"a", "ab", "b", "{x}", "{y}"}
+
+	s := NewStore()
+	for i := 0; i < 100; i++ {
+		exp := ""
+		for j, num := 0, 1+rnd.Intn(4); j < num; j++ {
+			exp += "/" + segments[rnd.Intn(len(segments))]
+		}
+		s.Add(exp)
+	}
+
+	for i := 0; i < 500; i++ {
+		in := ""
+		for j, num := 0, rnd.Intn(5); j < num; j++ {
+			in += "/" + []string{"a", "ab", "b", "c", ""}[rnd.Intn(5)]
+		}
+		found := s.Find(in)
+		trace := s.Explain(in)
+		if found == nil {
+			require.Nil(t, trace.Found, in)
+			require.True(t, trace.Consumed <= len(in), in)
+			continue
+		}
+		require.NotNil(t, trace.Found, in)
+		require.Equal(t, found.String(), trace.Found.String(), in)
+		require.Equal(t, len(in), trace.Consumed, in)
+	}
+}
+
+func mustParsePatterns(exps ...string) []*Pattern {
+	var res []*Pattern
+	for _, exp := range exps {
+		p, err := Parse(exp)
+		if err != nil {
+			panic(err)
+		}
+		res = append(res, p)
+	}
+	return res
+}
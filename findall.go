@@ -0,0 +1,113 @@
+package strparam
+
+import "strings"
+
+// FindAll returns all patterns of storage matched for incoming string (with values of parameters)
+// in order of the tree (specificity of patterns).
+//
+// Unlike Find all branches of tree are visited, each pattern is matched as by Lookup
+// (the value of parameter ends at the first occurrence of the next constant).
+// The result of Find is one of the matches (if found), but not always the first one:
+// Find returns the pattern reached by the walk of tree unless other match has higher priority.
+func (r *Store) FindAll(in string) []Match {
+	state := r.load()
+	tokens := make(Tokens, 0, state.maxSize)
+	var res []Match
+
+	walkAll(in, 0, state.root, &tokens, nil, func(end *node) {
+		match := Match{
+			Name:    end.Token.Raw,
+			Pattern: end.pattern,
+			Value:   end.value,
+			Params:  Params{},
+		}
+		for _, t := range tokens {
+			if t.Mode == PARAMETER_PARSED {
+				match.Params = append(match.Params, Param{Name: t.ParamName(), Value: t.Raw})
+			}
+		}
+		res = append(res, match)
+	})
+
+	return res
+}
+
+// Count returns the number of patterns of storage matched for incoming string (same as len(FindAll(in)),
+// but without extracting of parameters).
+func (r *Store) Count(in string) int {
+	res := 0
+	walkAll(in, 0, r.load().root, nil, nil, func(*node) {
+		res++
+	})
+	return res
+}
+
+// walkAll calls fn for each END node of the branch of tree matched to the incoming string from offset.
+//
+// Found tokens (constants, parsed parameters and END token) are appended to res (if not nil)
+// and are dropped after return. Branches are skipped if skip (if not nil) returns true for the node.
+func walkAll(in string, offset int, n *node, res *Tokens, skip func(n *node) bool, fn func(end *node)) {
+	if skip != nil && skip(n) {
+		return
+	}
+	size := 0
+	if res != nil {
+		size = len(*res)
+	}
+
+	switch n.Token.Mode {
+	case END:
+		if offset == len(in) {
+			appendToken(res, n.Token)
+			fn(n)
+		}
+	case CONST, SEPARATOR:
+		if !strings.HasPrefix(in[offset:], n.Token.Raw) {
+			return
+		}
+		appendToken(res, n.Token)
+		for _, child := range n.Childs {
+			walkAll(in, offset+n.Token.Len, child, res, skip, fn)
+		}
+	case PARAMETER:
+		for _, child := range n.Childs {
+			switch child.Token.Mode {
+			case END:
+				// trailing parameter captures the tail
+				if !n.Token.acceptsValue(in[offset:]) {
+					continue
+				}
+				appendToken(res, parsedParamToken(n, in[offset:]))
+				walkAll(in, len(in), child, res, skip, fn)
+			case CONST, SEPARATOR:
+				found := strings.Index(in[offset:], child.Token.Raw)
+				if found < 0 || !n.Token.acceptsValue(in[offset:offset+found]) {
+					continue
+				}
+				appendToken(res, parsedParamToken(n, in[offset:offset+found]))
+				walkAll(in, offset+found, child, res, skip, fn)
+			}
+			truncateTokens(res, size)
+		}
+	default:
+		// root and START nodes
+		appendToken(res, n.Token)
+		for _, child := range n.Childs {
+			walkAll(in, offset, child, res, skip, fn)
+		}
+	}
+
+	truncateTokens(res, size)
+}
+
+func appendToken(res *Tokens, t Token) {
+	if res != nil && t.Mode != UNKNOWN_TokenMode {
+		*res = append(*res, t)
+	}
+}
+
+func truncateTokens(res *Tokens, size int) {
+	if res != nil {
+		*res = (*res)[:size]
+	}
+}
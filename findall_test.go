@@ -0,0 +1,119 @@
+package strparam
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStore_FindAll(t *testing.T) {
+	s := NewStore()
+	s.AddNamed("index", "/")
+	s.AddNamed("param", "/{x}")
+	s.AddNamed("users", "/users/{id}")
+	s.AddNamed("user_files", "/users/{id}/files")
+	s.AddNamed("files", "/{any}/files")
+	require.NoError(t, s.AddPatternWithValue(&Pattern{
+		Tokens:    Tokens{StartToken, ConstToken("/"), ParameterToken("x"), SeparatorToken("/"), ParameterToken("tail"), NamedEndToken("sep")},
+		NumParams: 2,
+	}, 42))
+
+	got := s.FindAll("/users/1/files")
+	var names []string
+	for _, match := range got {
+		names = append(names, match.Name)
+	}
+	assert.Equal(t, []string{"user_files", "users", "sep", "param", "files"}, names)
+	assert.EqualValues(t, Params{{"id", "1"}}, got[0].Params)
+	assert.EqualValues(t, Params{{"id", "1/files"}}, got[1].Params)
+	assert.EqualValues(t, Params{{"x", "users"}, {"tail", "1/files"}}, got[2].Params)
+	assert.Equal(t, 42, got[2].Value)
+	assert.EqualValues(t, Params{{"x", "users/1/files"}}, got[3].Params)
+	assert.EqualValues(t, Params{{"any", "users/1"}}, got[4].Params)
+	assert.Equal(t, 5, s.Count("/users/1/files"))
+
+	got = s.FindAll("/")
+	require.Len(t, got, 2)
+	assert.Equal(t, "index", got[0].Name)
+	assert.Equal(t, Params{}, got[0].Params)
+	assert.Equal(t, "param", got[1].Name)
+	assert.EqualValues(t, Params{{"x", ""}}, got[1].Params)
+
+	assert.Empty(t, s.FindAll("users"))
+	assert.Equal(t, 0, s.Count("users"))
+	assert.Empty(t, NewStore().FindAll(""))
+	assert.Equal(t, 0, (&Store{}).Count(""))
+}
+
+// checks that FindAll returns the patterns matched by Lookup (in order of the tree)
+func TestStore_FindAll_SameAsLookup(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	segments := []string{"a", "ab", "b", "{x}", "{y}", "{z}"}
+
+	s := NewStore()
+	for i := 0; i < 100; i++ {
+		exp := ""
+		for j, num := 0, 1+rnd.Intn(4); j < num; j++ {
+			exp += "/" + segments[rnd.Intn(len(segments))]
+		}
+		_, err := s.AddNamed(fmt.Sprintf("p%d", i), exp)
+		require.NoError(t, err)
+	}
+	patterns := s.Patterns()
+
+	for i := 0; i < 500; i++ {
+		in := ""
+		for j, num := 0, rnd.Intn(5); j < num; j++ {
+			in += "/" + []string{"a", "ab", "b", "c", "", "ba"}[rnd.Intn(6)]
+		}
+
+		var want []Match
+		for _, p := range patterns {
+			if found, params := p.Lookup(in); found {
+				want = append(want, Match{Name: p.Name(), Pattern: p, Params: append(Params{}, params...)})
+			}
+		}
+		got := s.FindAll(in)
+		require.Equal(t, want, got, in)
+		require.Equal(t, len(want), s.Count(in), in)
+
+		if found := s.Find(in); found != nil {
+			require.NotEmpty(t, got, in)
+		}
+	}
+}
+
+func Benchmark_Store_FindAll_2_102(b *testing.B) {
+	r := NewStore()
+	for i := 0; i < 100; i++ {
+		r.Add(fmt.Sprintf("%s{p1}%s{p2}golang", RandAZ(4), RandAZ(4)))
+	}
+	r.Add("foo2{p1}foo2{p2}golang")
+	r.Add("foo1{p3}foo1{p4}golang")
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	in := "foo1XXXfoo1YYYgolang"
+	for i := 0; i < b.N; i++ {
+		r.FindAll(in)
+	}
+}
+
+func Benchmark_Store_Count_2_102(b *testing.B) {
+	r := NewStore()
+	for i := 0; i < 100; i++ {
+		r.Add(fmt.Sprintf("%s{p1}%s{p2}golang", RandAZ(4), RandAZ(4)))
+	}
+	r.Add("foo2{p1}foo2{p2}golang")
+	r.Add("foo1{p3}foo1{p4}golang")
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	in := "foo1XXXfoo1YYYgolang"
+	for i := 0; i < b.N; i++ {
+		r.Count(in)
+	}
+}
@@ -0,0 +1,90 @@
+package strparam
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Format returns string built from the pattern and values of parameters (the inverse of Lookup).
+//
+// Error is returned if
+// - value of parameter is missing or the parameter is unknown for the pattern
+// - resulting string is not parsed back into the same values of parameters by Lookup
+// (eg value of parameter contains the constant that follows the parameter)
+//
+// Constants are written as is.
+func (s *Pattern) Format(params Params) (string, error) {
+	values := make(map[string]string, len(params))
+	for _, param := range params {
+		if _, exists := values[param.Name]; exists {
+			return "", fmt.Errorf("duplicate parameter %q", param.Name)
+		}
+		values[param.Name] = param.Value
+	}
+	return s.FormatMap(values)
+}
+
+// MustFormat same as Format but panics if error.
+func (s *Pattern) MustFormat(params Params) string {
+	res, err := s.Format(params)
+	if err != nil {
+		panic(err)
+	}
+	return res
+}
+
+// FormatMap same as Format but values of parameters from the map.
+//
+// If several parameters are unknown the error is returned for the first one in sorted order of names.
+func (s *Pattern) FormatMap(values map[string]string) (string, error) {
+	if s == nil || len(s.Tokens) == 0 {
+		return "", errors.New("empty pattern")
+	}
+
+	known := make(map[string]bool, s.NumParams)
+	res := new(strings.Builder)
+
+	for _, t := range s.Tokens {
+		switch t.Mode {
+		case START, END:
+		case CONST, SEPARATOR:
+			res.WriteString(t.Raw)
+		case PARAMETER:
+			name := t.ParamName()
+			value, exists := values[name]
+			if !exists {
+				return "", fmt.Errorf("missing value of parameter %q", name)
+			}
+			known[name] = true
+			res.WriteString(value)
+		default:
+			return "", fmt.Errorf("not supported token type %v", t.Mode)
+		}
+	}
+
+	var unknown []string
+	for name := range values {
+		if !known[name] {
+			unknown = append(unknown, name)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return "", fmt.Errorf("unknown parameter %q", unknown[0])
+	}
+
+	// checks the round trip
+	found, params := s.Lookup(res.String())
+	if !found {
+		return "", fmt.Errorf("formatted string %q does not match to the pattern", res.String())
+	}
+	for _, param := range params {
+		if values[param.Name] != param.Value {
+			return "", fmt.Errorf("value %q of parameter %q breaks the pattern (parsed as %q)", values[param.Name], param.Name, param.Value)
+		}
+	}
+
+	return res.String(), nil
+}
@@ -0,0 +1,74 @@
+package strparam
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPattern_Format(t *testing.T) {
+	tests := []struct {
+		pattern string
+		params  Params
+		want    string
+		wantErr string
+	}{
+		{"foo", Params{}, "foo", ""},
+		{"foo", nil, "foo", ""},
+		{"foo{p1}bar", Params{{"p1", "123"}}, "foo123bar", ""},
+		{"foo{p1}bar", Params{{"p1", ""}}, "foobar", ""},
+		{"foo{p1}bar{p2}baz", Params{{"p2", "СЫР"}, {"p1", "日本語"}}, "foo日本語barСЫРbaz", ""},
+		{"{p1}qw{p2}", Params{{"p1", "1"}, {"p2", "qw"}}, "1qwqw", ""},
+		{"{{bar}", Params{{"bar", "123"}}, "{123", ""},
+
+		{"foo{p1}bar", Params{}, "", `missing value of parameter "p1"`},
+		{"foo{p1}bar", Params{{"p1", "1"}, {"p2", "2"}}, "", `unknown parameter "p2"`},
+		{"foo{p1}bar", Params{{"p1", "1"}, {"p1", "2"}}, "", `duplicate parameter "p1"`},
+		{"foo{p1}bar", Params{{"p1", "1bar2"}}, "", `formatted string "foo1bar2bar" does not match to the pattern`},
+		{"{p1}qw{p2}", Params{{"p1", "qw"}, {"p2", "1"}}, "", `value "qw" of parameter "p1" breaks the pattern (parsed as "")`},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%q->%v", tt.pattern, tt.params), func(t *testing.T) {
+			p, err := Parse(tt.pattern)
+			require.NoError(t, err)
+
+			got, err := p.Format(tt.params)
+			if tt.wantErr != "" {
+				require.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestPattern_FormatMap(t *testing.T) {
+	p, err := Parse("/users/{id}/posts/{post}")
+	require.NoError(t, err)
+
+	got, err := p.FormatMap(map[string]string{"id": "1", "post": "2"})
+	require.NoError(t, err)
+	assert.Equal(t, "/users/1/posts/2", got)
+
+	_, err = p.FormatMap(map[string]string{"id": "1"})
+	require.EqualError(t, err, `missing value of parameter "post"`)
+
+	// several unknown parameters: the error is the same for any order of the map
+	for i := 0; i < 20; i++ {
+		_, err = p.FormatMap(map[string]string{"id": "1", "post": "2", "c": "3", "b": "4", "a": "5"})
+		require.EqualError(t, err, `unknown parameter "a"`)
+	}
+
+	_, err = (*Pattern)(nil).FormatMap(nil)
+	require.EqualError(t, err, "empty pattern")
+}
+
+func TestPattern_MustFormat(t *testing.T) {
+	p, err := Parse("foo{p1}")
+	require.NoError(t, err)
+	assert.Equal(t, "foo1", p.MustFormat(Params{{"p1", "1"}}))
+	assert.Panics(t, func() { p.MustFormat(Params{}) })
+}
@@ -5,4 +5,5 @@ go 1.14
 require (
 	github.com/pkg/errors v0.9.1
 	github.com/stretchr/testify v1.5.1
+	gopkg.in/yaml.v2 v2.4.0
 )
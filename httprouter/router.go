@@ -12,16 +12,25 @@ import (
 
 // NewRouter returns routing.
 func NewRouter() *Router {
+	return NewRouterWithDialect(strparam.DefaultDialect)
+}
+
+// NewRouterWithDialect returns routing for paths written in the syntax of dialect
+// (eg strparam.ColonDialect for /users/:id).
+func NewRouterWithDialect(dialect strparam.Dialect) *Router {
 	return &Router{
-		store:       strparam.NewStore(),
-		handlersMap: make(map[string]http.HandlerFunc),
+		store:   strparam.NewStore(),
+		dialect: dialect,
+		routes:  make(map[string]bool),
 	}
 }
 
 // Router implements http routing.
 type Router struct {
-	store           *strparam.Store
-	handlersMap     map[string]http.HandlerFunc
+	store   *strparam.Store
+	dialect strparam.Dialect
+	// schemas of added routes (for check of duplicates)
+	routes          map[string]bool
 	ErrorHandler    http.HandlerFunc
 	NotFoundHandelr http.HandlerFunc
 }
@@ -51,12 +60,22 @@ func (r *Router) Add(method, addPath string, h http.HandlerFunc) error {
 		return errors.New("path cannot be has './.' or '/..'")
 	}
 
-	// forming an internal key
-	routeKey := ":" + method + ":" + addPath
-	routePattern, err := strparam.Parse(routeKey)
+	pathPattern, err := r.dialect("", addPath)
 	if err != nil {
-		return errors.Wrap(err, "failed parse route key")
+		return errors.Wrap(err, "failed parse route path")
+	}
+
+	// the path starts with a slash, so the first token of path (after START) is a constant
+	if len(pathPattern.Tokens) < 2 || pathPattern.Tokens[1].Mode != strparam.CONST {
+		return errors.New("addPath must start with a constant")
+	}
+
+	// forming an internal key (the method is added to the constant, independent of syntax of dialect)
+	routePattern := &strparam.Pattern{
+		Tokens:    strparam.Tokens{strparam.StartToken, strparam.ConstToken(":" + method + ":" + pathPattern.Tokens[1].Raw)},
+		NumParams: pathPattern.NumParams,
 	}
+	routePattern.Tokens = append(routePattern.Tokens, pathPattern.Tokens[2:]...)
 
 	xRoutePattern := &strparam.Pattern{
 		Tokens:    strparam.Tokens{},
@@ -79,15 +98,17 @@ func (r *Router) Add(method, addPath string, h http.HandlerFunc) error {
 		}
 	}
 
-	r.store.AddPattern(xRoutePattern)
-
-	// save the handler by hash of pattern
 	// if exists returns error
 	routePatternID := strparam.ListTokensSchemaString(xRoutePattern.Tokens)
-	if _, exists := r.handlersMap[routePatternID]; exists {
+	if r.routes[routePatternID] {
 		return fmt.Errorf("route %q already exists", addPath)
 	}
-	r.handlersMap[routePatternID] = h
+
+	// the handler is the value of pattern (returned by match)
+	if err := r.store.AddPatternWithValue(xRoutePattern, h); err != nil {
+		return errors.Wrap(err, "failed add route")
+	}
+	r.routes[routePatternID] = true
 
 	return nil
 }
@@ -107,24 +128,16 @@ func (r *Router) Find(method, requestPath string) (http.HandlerFunc, map[string]
 	// forming an internal key
 	routeKey := ":" + method + ":" + requestPath
 
-	// looking for a matche pattern
+	// looking for a matche pattern and parse the input string by it
 	// returns error if not exists
-	foundPattern := r.store.Find(routeKey)
-	if foundPattern == nil {
-		return nil, nil, errors.New("no matching patterns")
-	}
-
-	// parse the input string by pattern
-	matched, paramsList := foundPattern.Lookup(routeKey)
-	if !matched {
-		// TODO: when can this happen?
+	match, found := r.store.Match(routeKey)
+	if !found {
 		return nil, nil, errors.New("no matching patterns")
 	}
+	paramsList := match.Params
 
-	routePatternID := strparam.ListTokensSchemaString(foundPattern.Tokens)
-
-	routeHandler, found := r.handlersMap[routePatternID]
-	if !found {
+	routeHandler, ok := match.Value.(http.HandlerFunc)
+	if !ok {
 		return nil, nil, errors.New("not found handler by matched route")
 	}
 
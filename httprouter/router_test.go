@@ -12,6 +12,8 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"github.com/gebv/strparam"
 )
 
 func setupDemoRoutes(r *Router) {
@@ -237,3 +239,44 @@ func fText200(text string, keys ...string) http.HandlerFunc {
 		w.WriteHeader(http.StatusOK)
 	}
 }
+
+func TestNewRouterWithDialect(t *testing.T) {
+	r := NewRouterWithDialect(strparam.ColonDialect)
+	r.NotFoundHandelr = fText200("not found")
+	require.NoError(t, r.Add(http.MethodGet, "/users/:id", fText200("user %v", "id")))
+	require.NoError(t, r.Add(http.MethodGet, "/users/:id/files/*path", fText200("user %v file %v", "id", "path")))
+	require.EqualError(t, r.Add(http.MethodGet, "/files/*path/info", fText200("")), "failed parse route path: catch-all parameter should be at the end, pos 7")
+
+	cases := []struct {
+		in       string
+		wantBody string
+	}{
+		{"/users/1", "user 1"},
+		{"/users/1/files/a/b.txt", "user 1 file a/b.txt"},
+		{"/users/", "not found"},
+		{"/users/1/2", "not found"},
+		{"/files/a", "not found"},
+	}
+	for _, case_ := range cases {
+		t.Run(case_.in, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			request, err := http.NewRequest("GET", case_.in, nil)
+			require.NoError(t, err)
+			r.ServeHTTP(recorder, request)
+			assert.EqualValues(t, case_.wantBody, recorder.Body.String())
+		})
+	}
+}
+
+func TestRouter_Add_Duplicate(t *testing.T) {
+	r := NewRouter()
+	require.NoError(t, r.Add(http.MethodGet, "/users/{id}", fText200("user %v", "id")))
+	require.EqualError(t, r.Add(http.MethodGet, "/users/{id}", fText200("other %v", "id")), `route "/users/{id}" already exists`)
+
+	// the handler of the first route is kept
+	recorder := httptest.NewRecorder()
+	request, err := http.NewRequest("GET", "/users/1", nil)
+	require.NoError(t, err)
+	r.ServeHTTP(recorder, request)
+	assert.EqualValues(t, "user 1", recorder.Body.String())
+}
@@ -0,0 +1,78 @@
+// Package patternfile reads files of named patterns.
+//
+// The file contains a named pattern per line: name and pattern separated by whitespace.
+// The pattern is the rest of line as is (trailing whitespace is a part of pattern).
+// Empty lines and lines beginning with # are skipped.
+//
+//	# comment
+//	index /
+//	login user {name} logged in from {ip}
+package patternfile
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// Pattern named pattern from the file.
+type Pattern struct {
+	Name    string
+	Pattern string
+}
+
+// Read returns list of named patterns.
+//
+// Error is returned if the line is invalid or the name is duplicated or there are no patterns.
+func Read(r io.Reader) ([]Pattern, error) {
+	var res []Pattern
+	names := map[string]int{}
+
+	scanner := bufio.NewScanner(r)
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		// trailing whitespace is not trimmed because it is a part of pattern
+		line := strings.TrimLeft(scanner.Text(), " \t")
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		sep := strings.IndexAny(line, " \t")
+		if sep < 0 || strings.TrimLeft(line[sep:], " \t") == "" {
+			return nil, fmt.Errorf("line %d: expected name and pattern separated by whitespace", lineNum)
+		}
+		name, pattern := line[:sep], strings.TrimLeft(line[sep:], " \t")
+
+		if prev, exists := names[name]; exists {
+			return nil, fmt.Errorf("line %d: duplicate name %q (see line %d)", lineNum, name, prev)
+		}
+		names[name] = lineNum
+
+		res = append(res, Pattern{Name: name, Pattern: pattern})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(res) == 0 {
+		return nil, errors.New("no patterns")
+	}
+
+	return res, nil
+}
+
+// ReadFile same as Read but from the file.
+func ReadFile(path string) ([]Pattern, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	res, err := Read(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed read %q: %v", path, err)
+	}
+	return res, nil
+}
@@ -0,0 +1,34 @@
+package patternfile
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRead(t *testing.T) {
+	patterns, err := Read(strings.NewReader("# comment\n\nindex /\nlogin\tuser {name} logged in\n"))
+	require.NoError(t, err)
+	assert.EqualValues(t, []Pattern{{"index", "/"}, {"login", "user {name} logged in"}}, patterns)
+
+	// trailing whitespace is a part of pattern
+	patterns, err = Read(strings.NewReader("  \t\n  prompt\t$ \nindex /\t\n"))
+	require.NoError(t, err)
+	assert.EqualValues(t, []Pattern{{"prompt", "$ "}, {"index", "/\t"}}, patterns)
+
+	_, err = Read(strings.NewReader("index\n"))
+	require.EqualError(t, err, "line 1: expected name and pattern separated by whitespace")
+	_, err = Read(strings.NewReader("index \t \n"))
+	require.EqualError(t, err, "line 1: expected name and pattern separated by whitespace")
+	_, err = Read(strings.NewReader("index /\nindex /a\n"))
+	require.EqualError(t, err, `line 2: duplicate name "index" (see line 1)`)
+	_, err = Read(strings.NewReader("# comment\n"))
+	require.EqualError(t, err, "no patterns")
+}
+
+func TestReadFile(t *testing.T) {
+	_, err := ReadFile("notexists.txt")
+	require.Error(t, err)
+}
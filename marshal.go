@@ -0,0 +1,130 @@
+package strparam
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Source returns the text of pattern reconstructed from tokens (without name of pattern).
+//
+// For parsed patterns Parse(p.Source()) returns equivalent pattern.
+func (s Pattern) Source() string {
+	res := new(strings.Builder)
+	for _, t := range s.Tokens {
+		switch t.Mode {
+		case CONST, SEPARATOR, PARAMETER:
+			res.WriteString(t.Raw)
+		case PARAMETER_PARSED:
+			if t.Param != nil {
+				res.WriteString(t.Param.Raw)
+			}
+		}
+	}
+	return res.String()
+}
+
+// MarshalText implements encoding.TextMarshaler interface.
+//
+// Returns the text of pattern (see Source), only the body of pattern is encoded:
+// name of pattern (END token) is not included, so named and unnamed patterns with the same body
+// have the same text and UnmarshalText returns unnamed pattern. Use MarshalJSON to keep the name.
+// Error is returned if the pattern cannot be represented as text
+// (eg has SEPARATOR tokens or constants with border characters of parameters).
+func (s Pattern) MarshalText() ([]byte, error) {
+	source, err := s.canonicalSource()
+	if err != nil {
+		return nil, err
+	}
+	return []byte(source), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler interface.
+//
+// Returns unnamed pattern (the text has no name, see MarshalText).
+func (s *Pattern) UnmarshalText(text []byte) error {
+	parsed, err := Parse(string(text))
+	if err != nil {
+		return err
+	}
+	*s = *parsed
+	return nil
+}
+
+// jsonPattern is representation of the named pattern in JSON.
+type jsonPattern struct {
+	Name    string `json:"name"`
+	Pattern string `json:"pattern"`
+}
+
+// MarshalJSON implements json.Marshaler interface.
+//
+// Returns JSON string with the text of pattern
+// or JSON object {"name": "...", "pattern": "..."} if the pattern is named.
+func (s Pattern) MarshalJSON() ([]byte, error) {
+	source, err := s.canonicalSource()
+	if err != nil {
+		return nil, err
+	}
+	if s.Name() == "" {
+		return json.Marshal(source)
+	}
+	return json.Marshal(jsonPattern{Name: s.Name(), Pattern: source})
+}
+
+// UnmarshalJSON implements json.Unmarshaler interface.
+//
+// Accepts JSON string with the text of pattern or JSON object {"name": "...", "pattern": "..."}.
+func (s *Pattern) UnmarshalJSON(data []byte) error {
+	var in jsonPattern
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("{")) {
+		if err := json.Unmarshal(data, &in); err != nil {
+			return err
+		}
+	} else {
+		if err := json.Unmarshal(data, &in.Pattern); err != nil {
+			return err
+		}
+	}
+
+	parsed, err := ParseWithName(in.Name, in.Pattern)
+	if err != nil {
+		return err
+	}
+	*s = *parsed
+	return nil
+}
+
+// canonicalSource returns the text of pattern if it parsed back into the same tokens.
+func (s Pattern) canonicalSource() (string, error) {
+	source := s.Source()
+	parsed, err := ParseWithName(s.Name(), source)
+	if err != nil {
+		return "", fmt.Errorf("pattern cannot be represented as text: %v", err)
+	}
+	if !equalTokens(parsed.Tokens, s.Tokens) {
+		return "", fmt.Errorf("pattern cannot be represented as text: %q parsed as %s", source, parsed.Tokens.String())
+	}
+	return source, nil
+}
+
+func equalTokens(a, b Tokens) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !a[i].Equal(b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+var (
+	_ encoding.TextMarshaler   = Pattern{}
+	_ encoding.TextUnmarshaler = (*Pattern)(nil)
+	_ json.Marshaler           = Pattern{}
+	_ json.Unmarshaler         = (*Pattern)(nil)
+)
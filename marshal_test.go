@@ -0,0 +1,83 @@
+package strparam
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPattern_Source(t *testing.T) {
+	for _, tt := range patternBasicCases {
+		if tt.wantErr {
+			continue
+		}
+		t.Run(tt.pattern, func(t *testing.T) {
+			p, err := Parse(tt.pattern)
+			require.NoError(t, err)
+			assert.Equal(t, tt.pattern, p.Source())
+
+			reparsed, err := Parse(p.Source())
+			require.NoError(t, err)
+			assert.EqualValues(t, p, reparsed)
+		})
+	}
+}
+
+func TestPattern_MarshalText(t *testing.T) {
+	p, err := ParseWithName("name", "foo{p1}bar")
+	require.NoError(t, err)
+
+	text, err := p.MarshalText()
+	require.NoError(t, err)
+	assert.Equal(t, "foo{p1}bar", string(text))
+
+	var got Pattern
+	require.NoError(t, got.UnmarshalText(text))
+	assert.Equal(t, "foo{p1}bar", got.Source())
+	assert.Equal(t, "", got.Name())
+
+	// only the body is encoded: the name is lost, the same text as of unnamed pattern
+	unnamed, err := Parse("foo{p1}bar")
+	require.NoError(t, err)
+	unnamedText, err := unnamed.MarshalText()
+	require.NoError(t, err)
+	assert.Equal(t, unnamedText, text)
+	assert.EqualValues(t, *unnamed, got)
+	assert.NotEqual(t, p.Name(), got.Name())
+
+	require.EqualError(t, got.UnmarshalText([]byte("{foo")), "parameter was not closed, pos 3")
+
+	// not representable as text
+	_, err = Pattern{Tokens: Tokens{StartToken, ConstToken("a"), SeparatorToken("/"), EndToken}}.MarshalText()
+	require.EqualError(t, err, `pattern cannot be represented as text: "a/" parsed as START->Const("a/", len=2)->END`)
+	_, err = Pattern{Tokens: Tokens{StartToken, ConstToken("a{"), EndToken}}.MarshalText()
+	require.EqualError(t, err, `pattern cannot be represented as text: parameter was not closed, pos 1`)
+}
+
+func TestPattern_MarshalJSON(t *testing.T) {
+	type config struct {
+		Unnamed Pattern  `json:"unnamed"`
+		Named   *Pattern `json:"named"`
+		Empty   *Pattern `json:"empty"`
+	}
+
+	unnamed, err := Parse("foo={p1}")
+	require.NoError(t, err)
+	named, err := ParseWithName("bar", "bar={p2}")
+	require.NoError(t, err)
+
+	data, err := json.Marshal(config{Unnamed: *unnamed, Named: named})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"unnamed":"foo={p1}","named":{"name":"bar","pattern":"bar={p2}"},"empty":null}`, string(data))
+
+	var got config
+	require.NoError(t, json.Unmarshal(data, &got))
+	assert.EqualValues(t, *unnamed, got.Unnamed)
+	assert.EqualValues(t, named, got.Named)
+	assert.Nil(t, got.Empty)
+
+	require.Error(t, json.Unmarshal([]byte(`{"unnamed":"{}"}`), &got))
+	require.Error(t, json.Unmarshal([]byte(`{"unnamed":1}`), &got))
+}
@@ -26,8 +26,9 @@ func parse(patternName, exp string) (*Pattern, error) {
 		return nil, errors.New("expression should not is empty")
 	}
 
-	tokens := getlistTokens()
-	defer putlistTokens(tokens)
+	buf := getlistTokens()
+	defer putlistTokens(buf)
+	tokens := *buf
 
 	// end and start of parameter positions in bytes
 	var start, end int = 0, 0
@@ -117,8 +118,13 @@ func parse(patternName, exp string) (*Pattern, error) {
 		Raw:  patternName,
 	})
 
+	// copy because the list of tokens returns to the pool
+	*buf = tokens
+	res := make(Tokens, len(tokens))
+	copy(res, tokens)
+
 	return &Pattern{
-		Tokens:    tokens,
+		Tokens:    res,
 		NumParams: numParams,
 	}, nil
 }
@@ -133,14 +139,14 @@ func (s *Pattern) Lookup(in string) (bool, Params) {
 		return false, nil
 	}
 
-	params := getListParams()
-	defer putListParams(params)
-
 	if len(s.Tokens) == 0 {
 		// nothing not matches to anything
 		return false, nil
 	}
 
+	// NOTE: not from the pool because the list is returned to the caller
+	params := make(Params, 0, s.NumParams)
+
 	// this is the sum of the lengths of the patterns and found value of parameters
 	var offset int
 
@@ -163,6 +169,9 @@ func (s *Pattern) Lookup(in string) (bool, Params) {
 			_next := s.Tokens[num+1]
 			switch _next.Mode {
 			case END:
+				if !t.acceptsValue(in[offset:]) {
+					return false, nil
+				}
 				params = append(params, Param{
 					Name:  t.ParamName(),
 					Value: in[offset:],
@@ -170,6 +179,9 @@ func (s *Pattern) Lookup(in string) (bool, Params) {
 				offset += len(in) - offset
 			case CONST, SEPARATOR:
 				if found := strings.Index(in[offset:], _next.Raw); found > -1 {
+					if !t.acceptsValue(in[offset : offset+found]) {
+						return false, nil
+					}
 					params = append(params, Param{
 						Name:  t.ParamName(),
 						Value: in[offset : offset+found],
@@ -212,6 +224,11 @@ exitloop:
 type Pattern struct {
 	Tokens    Tokens
 	NumParams int
+	// Priority of pattern in Store (0 by default): among the patterns matching the input
+	// the pattern with higher priority is returned by Find and Match (see Store.AddWithPriority).
+	Priority int
+	// verbs of parameters if parsed from format string (see ParseFormat)
+	verbs []formatVerb
 }
 
 // String returns schema of pattern.
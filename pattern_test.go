@@ -305,3 +305,13 @@ func TestParseWithName(t *testing.T) {
 		})
 	}
 }
+
+func TestParse_TokensNotShared(t *testing.T) {
+	first, err := Parse("foo{p1}")
+	require.NoError(t, err)
+	for i := 0; i < 10; i++ {
+		_, err := Parse("bar{p2}baz")
+		require.NoError(t, err)
+	}
+	require.EqualValues(t, Tokens{StartToken, ConstToken("foo"), ParameterToken("p1"), EndToken}, first.Tokens)
+}
@@ -2,59 +2,47 @@ package strparam
 
 import "sync"
 
+// MaxListParamsCap is not used.
+//
+// Deprecated: lists of params are not pooled (the list returned by Lookup is owned by the caller).
 var MaxListParamsCap = 32
 var MaxListTokensCap = 128
 
+// pools keep pointers to lists of tokens (a slice put to the pool as is is allocated on every Put)
 var (
-	listParamsPool = sync.Pool{
-		New: func() interface{} { return make([]Param, 0, MaxListParamsCap) },
-	}
 	listTokensPool = sync.Pool{
-		New: func() interface{} { return make([]Token, 0, MaxListTokensCap) },
+		New: func() interface{} {
+			v := make([]Token, 0, MaxListTokensCap)
+			return &v
+		},
 	}
 )
 
-func getListParams() (v []Param) {
-	ifc := listParamsPool.Get()
-	if ifc != nil {
-		v = ifc.([]Param)
-	}
-	return
-}
-
-func putListParams(v []Param) {
-	if cap(v) <= MaxListParamsCap {
-		v = v[:0]
-		listParamsPool.Put(v)
-	}
+func getlistTokens() *[]Token {
+	return listTokensPool.Get().(*[]Token)
 }
 
-func getlistTokens() (v []Token) {
-	ifc := listTokensPool.Get()
-	if ifc != nil {
-		v = ifc.([]Token)
-	}
-	return
-}
-
-func putlistTokens(v []Token) {
-	if cap(v) <= MaxListTokensCap {
-		v = v[:0]
+func putlistTokens(v *[]Token) {
+	if cap(*v) <= MaxListTokensCap {
+		*v = (*v)[:0]
 		listTokensPool.Put(v)
 	}
 }
 
-func (s *Store) getlistTokens() (v []Token) {
-	ifc := s.tokensPool.Get()
-	if ifc != nil {
-		v = ifc.([]Token)
+func (s *Store) getlistTokens(size int) *[]Token {
+	v, _ := s.tokensPool.Get().(*[]Token)
+	if v == nil {
+		v = new([]Token)
+	}
+	if cap(*v) < size {
+		*v = make([]Token, 0, size)
 	}
-	return
+	return v
 }
 
-func (s *Store) putlistTokens(v []Token) {
-	if cap(v) <= MaxListTokensCap {
-		v = v[:0]
+func (s *Store) putlistTokens(v *[]Token) {
+	if cap(*v) <= MaxListTokensCap {
+		*v = (*v)[:0]
 		s.tokensPool.Put(v)
 	}
 }
@@ -0,0 +1,7 @@
+//go:build race
+// +build race
+
+package strparam
+
+// raceEnabled the race detector is enabled (sync.Pool drops items randomly, so allocations are not stable)
+const raceEnabled = true
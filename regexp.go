@@ -0,0 +1,527 @@
+package strparam
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"regexp/syntax"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Regexp returns the compiled regexp equivalent to the pattern (see RegexpString).
+func (s *Pattern) Regexp() (*regexp.Regexp, error) {
+	expr, err := s.RegexpString()
+	if err != nil {
+		return nil, err
+	}
+	return regexp.Compile(expr)
+}
+
+// RegexpString returns the regexp (RE2 syntax) with named groups of parameters
+// which matches the same inputs with the same values of parameters as Lookup.
+//
+// Tokens are converted as follows
+// - START - begin of text ^
+// - CONST and SEPARATOR - quoted constant
+// - PARAMETER followed by END - (?P<name>.*) captures the tail
+// - PARAMETER followed by CONST or SEPARATOR - (?P<name>...) captures the shortest value
+// up to the first occurrence of the constant (same as strings.Index in Lookup),
+// the value is expressed as "any text without earlier occurrence of the constant"
+// so the regexp does not backtrack to the next occurrences
+// - segment PARAMETER (see SegmentParameterToken) - same, but the value is non-empty and without slash
+// - PARAMETER_PARSED - (?P<name>...) with quoted value of parameter
+// - END (named or not) - end of text $, name of pattern is not part of regexp
+//
+// The flag s is set (. matches \n), the regexp is for Go semantics of ^ and $ (begin and end of text).
+// Inputs are expected to be valid UTF-8.
+//
+// Error is returned if the pattern cannot be represented (eg name of parameter is not valid name of group)
+// or never matches to anything (eg empty pattern).
+func (s *Pattern) RegexpString() (string, error) {
+	if s == nil || len(s.Tokens) == 0 {
+		return "", errors.New("empty pattern")
+	}
+
+	res := new(strings.Builder)
+	res.WriteString("(?s)^")
+
+	numParams := 0
+	for i, t := range s.Tokens {
+		switch t.Mode {
+		case START:
+		case END:
+			// Lookup ignores tokens after END
+			return s.finishRegexp(res, numParams)
+		case CONST, SEPARATOR:
+			res.WriteString(regexp.QuoteMeta(t.Raw))
+		case PARAMETER, PARAMETER_PARSED:
+			name := t.ParamName()
+			if !isRegexpGroupName(name) {
+				return "", fmt.Errorf("name of parameter %q is not valid name of regexp group", name)
+			}
+			numParams++
+
+			if t.Mode == PARAMETER_PARSED {
+				fmt.Fprintf(res, "(?P<%s>%s)", name, regexp.QuoteMeta(t.Raw))
+				continue
+			}
+
+			if i+1 >= len(s.Tokens) {
+				return "", fmt.Errorf("parameter %q should be followed by a constant or the end", name)
+			}
+			switch next := s.Tokens[i+1]; next.Mode {
+			case END:
+				if t.Segment {
+					fmt.Fprintf(res, "(?P<%s>[^/]+)", name)
+				} else {
+					fmt.Fprintf(res, "(?P<%s>.*)", name)
+				}
+			case CONST, SEPARATOR:
+				fmt.Fprintf(res, "(?P<%s>%s)", name, beforeFirstOccurrence(next.Raw, t.Segment))
+			default:
+				return "", fmt.Errorf("parameter %q should be followed by a constant or the end", name)
+			}
+		default:
+			return "", fmt.Errorf("not supported token type %v", t.Mode)
+		}
+	}
+
+	return s.finishRegexp(res, numParams)
+}
+
+func (s *Pattern) finishRegexp(res *strings.Builder, numParams int) (string, error) {
+	if numParams != s.NumParams {
+		// Lookup checks the number of parameters
+		return "", fmt.Errorf("pattern never matches: number of parameters is %d, expected %d", numParams, s.NumParams)
+	}
+	res.WriteString("$")
+	return res.String(), nil
+}
+
+func isRegexpGroupName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for _, char := range name {
+		if char != '_' && (char > unicode.MaxASCII || !unicode.IsLetter(char) && !unicode.IsDigit(char)) {
+			return false
+		}
+	}
+	return true
+}
+
+// beforeFirstOccurrence returns the regexp of texts w such that the first occurrence of sep in w+sep
+// is at the end of w (w does not contain sep and does not end with a part of an earlier occurrence).
+// If segment is true then w is also non-empty and does not contain slash.
+//
+// The regexp is built from the automaton of searching sep (Knuth–Morris–Pratt) without the final state.
+func beforeFirstOccurrence(sep string, segment bool) string {
+	runes := []rune(sep)
+	if len(runes) == 1 {
+		if segment {
+			return renderRegexp(&reNode{op: opClass, set: uniqueRunes([]rune{runes[0], '/'}), negated: true}) + "+"
+		}
+		return renderRegexp(&reNode{op: opClass, set: []rune{runes[0]}, negated: true}) + "*"
+	}
+
+	// alphabet is the runes of sep and any other rune
+	alphabet := uniqueRunes(runes)
+	// runes of w (slash is not allowed in segment)
+	allowed := alphabet
+	other := alphabet
+	if segment {
+		allowed = make([]rune, 0, len(alphabet))
+		for _, char := range alphabet {
+			if char != '/' {
+				allowed = append(allowed, char)
+			}
+		}
+		other = uniqueRunes(append(append([]rune{}, alphabet...), '/'))
+	}
+
+	// automaton: state k is "k runes of sep are matched"
+	fail := make([]int, len(runes)+1)
+	for k := 2; k <= len(runes); k++ {
+		f := fail[k-1]
+		for f > 0 && runes[f] != runes[k-1] {
+			f = fail[f]
+		}
+		if runes[f] == runes[k-1] {
+			f++
+		}
+		fail[k] = f
+	}
+	delta := func(state int, char rune) int {
+		for state > 0 && runes[state] != char {
+			state = fail[state]
+		}
+		if runes[state] == char {
+			state++
+		}
+		return state
+	}
+
+	// state from which the rest of sep is read without earlier occurrence of sep
+	accepted := func(state int) bool {
+		for _, char := range runes[:len(runes)-1] {
+			state = delta(state, char)
+			if state == len(runes) {
+				return false
+			}
+		}
+		return true
+	}
+
+	// generalized automaton: states of sep 0..n-1, start n, final n+1
+	n := len(runes)
+	start, final := n, n+1
+	edges := make([][]*reNode, n+2)
+	for i := range edges {
+		edges[i] = make([]*reNode, n+2)
+	}
+	for state := 0; state < n; state++ {
+		for _, char := range allowed {
+			if next := delta(state, char); next < n {
+				edges[state][next] = reAlt(edges[state][next], &reNode{op: opClass, set: []rune{char}})
+			}
+		}
+		// any other rune resets the automaton
+		edges[state][0] = reAlt(edges[state][0], &reNode{op: opClass, set: other, negated: true})
+	}
+	if segment {
+		// non-empty: the start reads the first rune as the state 0
+		copy(edges[start], edges[0][:n])
+	} else {
+		edges[start][0] = &reNode{op: opEmpty}
+	}
+	for state := 0; state < n; state++ {
+		if accepted(state) {
+			edges[state][final] = reAlt(edges[state][final], &reNode{op: opEmpty})
+		}
+	}
+
+	// eliminates states of sep
+	for k := n - 1; k >= 0; k-- {
+		loop := reStar(edges[k][k])
+		for i := range edges {
+			if i == k || edges[i][k] == nil {
+				continue
+			}
+			for j := range edges {
+				if j == k || edges[k][j] == nil {
+					continue
+				}
+				edges[i][j] = reAlt(edges[i][j], reConcat(edges[i][k], reConcat(loop, edges[k][j])))
+			}
+		}
+		for i := range edges {
+			edges[i][k], edges[k][i] = nil, nil
+		}
+	}
+
+	return renderRegexp(edges[start][final])
+}
+
+func uniqueRunes(runes []rune) []rune {
+	res := make([]rune, 0, len(runes))
+	for _, char := range runes {
+		if !containsRune(res, char) {
+			res = append(res, char)
+		}
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i] < res[j] })
+	return res
+}
+
+func containsRune(list []rune, char rune) bool {
+	for _, item := range list {
+		if item == char {
+			return true
+		}
+	}
+	return false
+}
+
+// operations of regular expressions (nil node is the empty set)
+const (
+	// opEmpty the empty text
+	opEmpty = iota
+	// opClass a rune of the set (or not of the set if negated)
+	opClass
+	opConcat
+	opAlt
+	opStar
+)
+
+// reNode node of a regular expression used for building the regexp.
+type reNode struct {
+	op      int
+	set     []rune
+	negated bool
+	subs    []*reNode
+}
+
+func reAlt(a, b *reNode) *reNode {
+	switch {
+	case a == nil:
+		return b
+	case b == nil:
+		return a
+	case renderRegexp(a) == renderRegexp(b):
+		return a
+	case a.op == opClass && b.op == opClass:
+		return unionClasses(a, b)
+	}
+
+	var subs []*reNode
+	for _, item := range []*reNode{a, b} {
+		if item.op == opAlt {
+			subs = append(subs, item.subs...)
+		} else {
+			subs = append(subs, item)
+		}
+	}
+	return &reNode{op: opAlt, subs: subs}
+}
+
+func unionClasses(a, b *reNode) *reNode {
+	if a.negated && b.negated {
+		// not A or not B = not (A and B)
+		var set []rune
+		for _, char := range a.set {
+			if containsRune(b.set, char) {
+				set = append(set, char)
+			}
+		}
+		return &reNode{op: opClass, set: set, negated: true}
+	}
+	if a.negated || b.negated {
+		if b.negated {
+			a, b = b, a
+		}
+		// not A or B = not (A without B)
+		var set []rune
+		for _, char := range a.set {
+			if !containsRune(b.set, char) {
+				set = append(set, char)
+			}
+		}
+		return &reNode{op: opClass, set: set, negated: true}
+	}
+	return &reNode{op: opClass, set: uniqueRunes(append(append([]rune{}, a.set...), b.set...))}
+}
+
+func reConcat(a, b *reNode) *reNode {
+	switch {
+	case a == nil || b == nil:
+		return nil
+	case a.op == opEmpty:
+		return b
+	case b.op == opEmpty:
+		return a
+	}
+
+	var subs []*reNode
+	for _, item := range []*reNode{a, b} {
+		if item.op == opConcat {
+			subs = append(subs, item.subs...)
+		} else {
+			subs = append(subs, item)
+		}
+	}
+	return &reNode{op: opConcat, subs: subs}
+}
+
+func reStar(a *reNode) *reNode {
+	if a == nil || a.op == opEmpty {
+		return &reNode{op: opEmpty}
+	}
+	if a.op == opStar {
+		return a
+	}
+	return &reNode{op: opStar, subs: []*reNode{a}}
+}
+
+// renderRegexp returns the regexp in RE2 syntax (the empty set is rendered as never matched class).
+func renderRegexp(n *reNode) string {
+	if n == nil {
+		return `[^\x00-\x{10FFFF}]`
+	}
+
+	switch n.op {
+	case opEmpty:
+		return ""
+	case opClass:
+		if !n.negated && len(n.set) == 1 {
+			return regexp.QuoteMeta(string(n.set[0]))
+		}
+		if n.negated && len(n.set) == 0 {
+			return "."
+		}
+		res := new(strings.Builder)
+		res.WriteString("[")
+		if n.negated {
+			res.WriteString("^")
+		}
+		for _, char := range n.set {
+			if char < utf8.RuneSelf && !unicode.IsLetter(char) && !unicode.IsDigit(char) {
+				fmt.Fprintf(res, `\x%02x`, char)
+			} else {
+				res.WriteRune(char)
+			}
+		}
+		res.WriteString("]")
+		return res.String()
+	case opConcat:
+		res := new(strings.Builder)
+		for _, sub := range n.subs {
+			if sub.op == opAlt && !isOptional(sub) {
+				res.WriteString("(?:" + renderRegexp(sub) + ")")
+			} else {
+				res.WriteString(renderRegexp(sub))
+			}
+		}
+		return res.String()
+	case opAlt:
+		var items []string
+		optional := false
+		for _, sub := range n.subs {
+			if sub.op == opEmpty {
+				optional = true
+				continue
+			}
+			items = append(items, renderRegexp(sub))
+		}
+		if optional {
+			return "(?:" + strings.Join(items, "|") + ")?"
+		}
+		return strings.Join(items, "|")
+	case opStar:
+		sub := n.subs[0]
+		if sub.op == opClass {
+			return renderRegexp(sub) + "*"
+		}
+		return "(?:" + renderRegexp(sub) + ")*"
+	}
+
+	panic(fmt.Sprintf("not supported operation %d", n.op))
+}
+
+// isOptional returns true if the alternation contains the empty text (rendered as group with ?).
+func isOptional(n *reNode) bool {
+	for _, sub := range n.subs {
+		if sub.op == opEmpty {
+			return true
+		}
+	}
+	return false
+}
+
+// FromRegexp returns the pattern converted from the regexp.
+//
+// Supported subset of regexps (Perl syntax):
+// - anchored at the begin and at the end of text ^...$ (or \A...\z)
+// - constants (literals, escaped characters, single characters in classes)
+// - groups of parameters (.*) (.+) (?P<name>.*) (?P<name>.+) and non-greedy forms,
+// unnamed parameters are named by number of group (p1, p2, ...)
+// - parameters should be separated by constants
+//
+// The values of parameters are found as Lookup does: up to the first occurrence of the next constant
+// (unlike greedy groups of regexp) and the value may be empty (unlike .+).
+// So results may differ from the regexp for inputs with repeated constants.
+//
+// Error is returned with the unsupported construct (eg character classes, alternations, repetitions).
+func FromRegexp(expr string) (*Pattern, error) {
+	re, err := syntax.Parse(expr, syntax.Perl)
+	if err != nil {
+		return nil, err
+	}
+
+	var subs []*syntax.Regexp
+	if re.Op == syntax.OpConcat {
+		subs = re.Sub
+	} else {
+		subs = []*syntax.Regexp{re}
+	}
+
+	if len(subs) == 0 || subs[0].Op != syntax.OpBeginText && subs[0].Op != syntax.OpBeginLine {
+		return nil, errors.New("regexp should be anchored at the begin of text (^)")
+	}
+	if len(subs) == 1 || subs[len(subs)-1].Op != syntax.OpEndText && subs[len(subs)-1].Op != syntax.OpEndLine {
+		return nil, errors.New("regexp should be anchored at the end of text ($)")
+	}
+	subs = subs[1 : len(subs)-1]
+	if len(subs) == 0 {
+		return nil, errors.New("empty pattern")
+	}
+
+	tokens := Tokens{StartToken}
+	names := map[string]bool{}
+	for _, sub := range subs {
+		switch sub.Op {
+		case syntax.OpLiteral:
+			if sub.Flags&syntax.FoldCase != 0 {
+				return nil, fmt.Errorf("not supported case-insensitive literal %q", sub.String())
+			}
+			if last := &tokens[len(tokens)-1]; last.Mode == CONST {
+				*last = ConstToken(last.Raw + string(sub.Rune))
+				continue
+			}
+			tokens = append(tokens, ConstToken(string(sub.Rune)))
+		case syntax.OpCapture:
+			if !isAnyText(sub.Sub[0]) {
+				return nil, fmt.Errorf("not supported group %q: group should be (.*) or (.+)", sub.String())
+			}
+			if tokens[len(tokens)-1].Mode == PARAMETER {
+				return nil, fmt.Errorf("not supported group %q: parameters should be separated by a constant", sub.String())
+			}
+			name := sub.Name
+			if name == "" {
+				name = fmt.Sprintf("p%d", sub.Cap)
+			}
+			if names[name] {
+				return nil, fmt.Errorf("duplicate name of parameter %q", name)
+			}
+			names[name] = true
+			tokens = append(tokens, ParameterToken(name))
+		default:
+			return nil, fmt.Errorf("not supported %s %q", describeRegexpOp(sub), sub.String())
+		}
+	}
+	tokens = append(tokens, EndToken)
+
+	return &Pattern{Tokens: tokens, NumParams: len(names)}, nil
+}
+
+// isAnyText returns true for .* and .+ (greedy or not, . matches \n or not).
+func isAnyText(re *syntax.Regexp) bool {
+	if re.Op != syntax.OpStar && re.Op != syntax.OpPlus {
+		return false
+	}
+	switch re.Sub[0].Op {
+	case syntax.OpAnyChar, syntax.OpAnyCharNotNL:
+		return true
+	}
+	return false
+}
+
+func describeRegexpOp(re *syntax.Regexp) string {
+	switch re.Op {
+	case syntax.OpCharClass, syntax.OpAnyChar, syntax.OpAnyCharNotNL:
+		return "character class"
+	case syntax.OpStar, syntax.OpPlus, syntax.OpQuest, syntax.OpRepeat:
+		return "repetition outside of group"
+	case syntax.OpAlternate:
+		return "alternation"
+	case syntax.OpBeginLine, syntax.OpEndLine, syntax.OpBeginText, syntax.OpEndText:
+		return "anchor inside of regexp"
+	case syntax.OpWordBoundary, syntax.OpNoWordBoundary:
+		return "word boundary"
+	case syntax.OpEmptyMatch, syntax.OpNoMatch:
+		return "empty group"
+	}
+	return "construct"
+}
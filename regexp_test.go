@@ -0,0 +1,229 @@
+package strparam
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPattern_RegexpString(t *testing.T) {
+	tests := []struct {
+		tokens  Tokens
+		want    string
+		wantErr string
+	}{
+		{
+			Tokens{StartToken, ConstToken("foo=("), ParameterToken("p1"), ConstToken(")"), EndToken},
+			`(?s)^foo=\((?P<p1>[^\x29]*)\)$`,
+			"",
+		},
+		{
+			Tokens{StartToken, ConstToken("/"), ParameterToken("id"), EndToken},
+			`(?s)^/(?P<id>.*)$`,
+			"",
+		},
+		{
+			Tokens{StartToken, ParameterToken("a"), SeparatorToken("ab"), NamedEndToken("name")},
+			`(?s)^(?P<a>(?:[^a]|aa*[^ab])*(?:aa*)?)ab$`,
+			"",
+		},
+		{
+			Tokens{StartToken, ConstToken("/users/"), SegmentParameterToken("id"), EndToken},
+			`(?s)^/users/(?P<id>[^/]+)$`,
+			"",
+		},
+		{
+			Tokens{StartToken, ConstToken("/"), SegmentParameterToken("name"), ConstToken("."), SegmentParameterToken("ext"), EndToken},
+			`(?s)^/(?P<name>[^\x2e\x2f]+)\.(?P<ext>[^/]+)$`,
+			"",
+		},
+		{
+			Tokens{StartToken, ConstToken("v"), ParsedParameterToken("id", "1.0"), EndToken},
+			`(?s)^v(?P<id>1\.0)$`,
+			"",
+		},
+		{
+			Tokens{StartToken, ConstToken("a"), EndToken, ConstToken("b")},
+			`(?s)^a$`,
+			"",
+		},
+		{nil, "", "empty pattern"},
+		{
+			Tokens{StartToken, ParameterToken("user name"), EndToken},
+			"",
+			`name of parameter "user name" is not valid name of regexp group`,
+		},
+		{
+			Tokens{StartToken, ParameterToken("a")},
+			"",
+			`parameter "a" should be followed by a constant or the end`,
+		},
+		{
+			Tokens{StartToken, ParameterToken("a"), ParsedParameterToken("b", "1"), EndToken},
+			"",
+			`parameter "a" should be followed by a constant or the end`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.tokens.String(), func(t *testing.T) {
+			p := &Pattern{Tokens: tt.tokens}
+			for _, token := range tt.tokens {
+				if token.Mode == PARAMETER || token.Mode == PARAMETER_PARSED {
+					p.NumParams++
+				}
+			}
+			got, err := p.RegexpString()
+			if tt.wantErr != "" {
+				require.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	_, err := (&Pattern{Tokens: Tokens{StartToken, ConstToken("a"), EndToken}, NumParams: 1}).RegexpString()
+	require.EqualError(t, err, "pattern never matches: number of parameters is 0, expected 1")
+}
+
+func TestPattern_Regexp(t *testing.T) {
+	p, err := Parse("foo=({p1}), baz=({p2}), golang")
+	require.NoError(t, err)
+	re, err := p.Regexp()
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"", "p1", "p2"}, re.SubexpNames())
+	assert.Equal(t,
+		[]string{"foo=(bar), baz=(日本語), golang", "bar", "日本語"},
+		re.FindStringSubmatch("foo=(bar), baz=(日本語), golang"),
+	)
+	// the first occurrence of the constant (same as Lookup)
+	assert.Nil(t, re.FindStringSubmatch("foo=(a), baz=(b), golang), baz=(c), golang"))
+	found, _ := p.Lookup("foo=(a), baz=(b), golang), baz=(c), golang")
+	assert.False(t, found)
+}
+
+// checks that the regexp of pattern matches exactly as Lookup on random patterns and inputs
+func TestPattern_Regexp_SameAsLookup(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	const alphabet = "ab/"
+	randString := func(max int) string {
+		res := make([]byte, rnd.Intn(max+1))
+		for i := range res {
+			res[i] = alphabet[rnd.Intn(len(alphabet))]
+		}
+		return string(res)
+	}
+
+	for i := 0; i < 300; i++ {
+		// random pattern of constants and parameters
+		// and a generator of inputs similar to the pattern
+		expr := new(strings.Builder)
+		var parts []func() string
+		param := rnd.Intn(2) == 0
+		for j, num := 0, 1+rnd.Intn(4); j < num; j++ {
+			if param {
+				fmt.Fprintf(expr, "{p%d}", j)
+				parts = append(parts, func() string { return randString(4) })
+			} else {
+				constant := string(alphabet[rnd.Intn(len(alphabet))]) + randString(2)
+				expr.WriteString(constant)
+				parts = append(parts, func() string { return constant })
+			}
+			param = !param
+		}
+		p, err := Parse(expr.String())
+		require.NoError(t, err)
+		if rnd.Intn(2) == 0 {
+			// parameters limited by segment of path
+			for k := range p.Tokens {
+				p.Tokens[k].Segment = p.Tokens[k].Mode == PARAMETER
+			}
+		}
+		re, err := p.Regexp()
+		require.NoError(t, err, expr.String())
+
+		for j := 0; j < 200; j++ {
+			in := randString(10)
+			if j%2 == 0 {
+				in = ""
+				for _, part := range parts {
+					in += part()
+				}
+			}
+			found, params := p.Lookup(in)
+			match := re.FindStringSubmatch(in)
+			require.Equal(t, found, match != nil, "pattern %q (%s), input %q", expr, re, in)
+			if !found {
+				continue
+			}
+			values := make([]string, 0, len(params))
+			for _, param := range params {
+				values = append(values, param.Value)
+			}
+			require.Equal(t, values, match[1:], "pattern %q (%s), input %q", expr, re, in)
+		}
+	}
+}
+
+func TestFromRegexp(t *testing.T) {
+	tests := []struct {
+		expr    string
+		want    Tokens
+		wantErr string
+	}{
+		{
+			`^foo=\((.*)\), baz=\((.*)\), golang$`,
+			Tokens{StartToken, ConstToken("foo=("), ParameterToken("p1"), ConstToken("), baz=("), ParameterToken("p2"), ConstToken("), golang"), EndToken},
+			"",
+		},
+		{
+			`\A/users/(?P<id>.+?)/[.]json(?s:(?P<rest>.*))\z`,
+			Tokens{StartToken, ConstToken("/users/"), ParameterToken("id"), ConstToken("/.json"), ParameterToken("rest"), EndToken},
+			"",
+		},
+		{
+			`^(.*){}(?:a)$`,
+			Tokens{StartToken, ParameterToken("p1"), ConstToken("{}a"), EndToken},
+			"",
+		},
+		{`^(.*`, nil, "error parsing regexp: missing closing ): `^(.*`"},
+		{`foo$`, nil, "regexp should be anchored at the begin of text (^)"},
+		{`^foo`, nil, "regexp should be anchored at the end of text ($)"},
+		{`^$`, nil, "empty pattern"},
+		{`^(?i)foo$`, nil, `not supported case-insensitive literal "(?i:FOO)"`},
+		{`^id=([0-9]+)$`, nil, `not supported group "([0-9]+)": group should be (.*) or (.+)`},
+		{`^(.*)(.*)$`, nil, `not supported group "(?-s:(.*))": parameters should be separated by a constant`},
+		{`^(?P<a>.*),(?P<a>.*)$`, nil, `duplicate name of parameter "a"`},
+		{`^(?P<p2>.*),(.*)$`, nil, `duplicate name of parameter "p2"`},
+		{`^id=[0-9]$`, nil, `not supported character class "[0-9]"`},
+		{`^id=.*$`, nil, `not supported repetition outside of group "(?-s:.*)"`},
+		{`^(foo|bar)$`, nil, `not supported group "(foo|bar)": group should be (.*) or (.+)`},
+		{`^foo|bar$`, nil, "regexp should be anchored at the begin of text (^)"},
+		{`^a\bb$`, nil, `not supported word boundary "\\b"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			got, err := FromRegexp(tt.expr)
+			if tt.wantErr != "" {
+				require.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want.String(), got.Tokens.String())
+		})
+	}
+}
+
+func TestFromRegexp_Lookup(t *testing.T) {
+	p, err := FromRegexp(`^foo=\((.*)\), baz=\((.*)\), golang$`)
+	require.NoError(t, err)
+	found, params := p.Lookup("foo=(bar), baz=(日本語), golang")
+	assert.True(t, found)
+	assert.EqualValues(t, Params{{"p1", "bar"}, {"p2", "日本語"}}, params)
+	assert.Equal(t, "foo=({p1}), baz=({p2}), golang", p.Source())
+}
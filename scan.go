@@ -0,0 +1,311 @@
+package strparam
+
+import (
+	"sort"
+	"strings"
+)
+
+// Scan returns all non-overlapping occurrences of the patterns from storage in the text.
+//
+// The text is scanned from left to right, at each step the leftmost occurrence wins.
+// If several patterns are matched at the same position then wins the first by sorting rules of the tree.
+// Parameters are lazy (as in Pattern.FindAll):
+// - a leading parameter captures the text from the end of previous occurrence (or from begin of text)
+// - a trailing parameter captures the tail of text
+//
+// Candidate positions are found by a single pass of the text with
+// multi-string search (Aho–Corasick) by the first constants of the patterns.
+func (r *Store) Scan(text string) []Occurrence {
+	sc := r.getScanner()
+	if sc == nil {
+		return nil
+	}
+
+	var hits []scanHit
+	sc.anchors.each(text, func(start, keyword int) {
+		hits = append(hits, scanHit{start: start, keyword: keyword})
+	})
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].start != hits[j].start {
+			return hits[i].start < hits[j].start
+		}
+		return hits[i].keyword < hits[j].keyword
+	})
+
+	var res []Occurrence
+	var prevEnd, h int
+
+	for {
+		// skips the hits inside of previous occurrence
+		for h < len(hits) && hits[h].start < prevEnd {
+			h++
+		}
+
+		occ, ok := sc.scanFrom(text, prevEnd, hits[h:])
+		if !ok {
+			break
+		}
+		res = append(res, occ)
+
+		if occ.End == occ.Start || occ.End >= len(text) {
+			// nothing more can be found
+			break
+		}
+		prevEnd = occ.End
+	}
+
+	return res
+}
+
+// scanner is helper for Store.Scan (built by the current state of the tree).
+type scanner struct {
+	// the first constants of the patterns
+	anchors *acMatcher
+	// nodes of the first constants (same order as keywords in anchors)
+	constNodes []*node
+	// nodes of the leading parameters
+	paramNodes []*node
+}
+
+type scanHit struct {
+	start   int
+	keyword int
+}
+
+func (r *Store) getScanner() *scanner {
+	state := r.load()
+	state.scannerOnce.Do(func() {
+		state.scanner = newScanner(state.root)
+	})
+	return state.scanner
+}
+
+// newScanner returns scanner by the tree (nil if the tree is empty).
+func newScanner(root *node) *scanner {
+	sc := &scanner{}
+	keywords := []string{}
+	for _, start := range root.Childs {
+		if start.Token.Mode != START {
+			continue
+		}
+		for _, child := range start.Childs {
+			switch child.Token.Mode {
+			case CONST, SEPARATOR:
+				keywords = append(keywords, child.Token.Raw)
+				sc.constNodes = append(sc.constNodes, child)
+			case PARAMETER:
+				sc.paramNodes = append(sc.paramNodes, child)
+			}
+		}
+	}
+
+	if len(sc.constNodes) == 0 && len(sc.paramNodes) == 0 {
+		return nil
+	}
+
+	sc.anchors = newACMatcher(keywords)
+	return sc
+}
+
+// scanFrom returns the leftmost occurrence from offset.
+// Hits are sorted candidates of the first constants.
+func (sc *scanner) scanFrom(text string, offset int, hits []scanHit) (Occurrence, bool) {
+	tokens := Tokens{}
+
+	// patterns with the first constant at the offset has the highest weight
+	for len(hits) > 0 && hits[0].start == offset {
+		if end, ok := walkBranch(text, offset, sc.constNodes[hits[0].keyword], &tokens, false); ok {
+			return newOccurrence(offset, end, tokens), true
+		}
+		hits = hits[1:]
+	}
+
+	// the leading parameter always starts at the offset
+	for _, paramNode := range sc.paramNodes {
+		if end, ok := walkBranch(text, offset, paramNode, &tokens, true); ok {
+			return newOccurrence(offset, end, tokens), true
+		}
+	}
+
+	for _, hit := range hits {
+		if end, ok := walkBranch(text, hit.start, sc.constNodes[hit.keyword], &tokens, false); ok {
+			return newOccurrence(hit.start, end, tokens), true
+		}
+	}
+
+	return Occurrence{}, false
+}
+
+// newOccurrence returns occurrence by found list of tokens (END token is last).
+func newOccurrence(start, end int, tokens Tokens) Occurrence {
+	occ := Occurrence{
+		Start:  start,
+		End:    end,
+		Params: Params{},
+	}
+	for _, t := range tokens {
+		switch t.Mode {
+		case PARAMETER_PARSED:
+			occ.Params = append(occ.Params, Param{Name: t.ParamName(), Value: t.Raw})
+		case END:
+			occ.Name = t.Raw
+		}
+	}
+	return occ
+}
+
+// walkBranch returns true if the branch of tree from current node matches to the text from offset
+// (not anchored at end of text). Returns end offset of match.
+//
+// Found tokens (constants, parsed parameters and END token) are appended to res.
+// If retry is true then the value of parameter can be extended to the next occurrences of the constant.
+func walkBranch(text string, offset int, n *node, res *Tokens, retry bool) (int, bool) {
+	size := len(*res)
+
+	switch n.Token.Mode {
+	case END:
+		*res = append(*res, n.Token)
+		return offset, true
+	case CONST, SEPARATOR:
+		if !strings.HasPrefix(text[offset:], n.Token.Raw) {
+			return 0, false
+		}
+		*res = append(*res, n.Token)
+		offset += n.Token.Len
+
+		for _, child := range n.Childs {
+			if end, ok := walkBranch(text, offset, child, res, false); ok {
+				return end, true
+			}
+		}
+	case PARAMETER:
+		for _, child := range n.Childs {
+			switch child.Token.Mode {
+			case END:
+				// trailing parameter captures the tail
+				if !n.Token.acceptsValue(text[offset:]) {
+					continue
+				}
+				*res = append(*res, parsedParamToken(n, text[offset:]), child.Token)
+				return len(text), true
+			case CONST, SEPARATOR:
+				for pos := offset; pos <= len(text); pos++ {
+					found := strings.Index(text[pos:], child.Token.Raw)
+					if found < 0 {
+						break
+					}
+					pos += found
+
+					if n.Token.acceptsValue(text[offset:pos]) {
+						*res = append(*res, parsedParamToken(n, text[offset:pos]))
+						if end, ok := walkBranch(text, pos, child, res, false); ok {
+							return end, true
+						}
+						*res = (*res)[:size]
+					}
+
+					if !retry {
+						break
+					}
+				}
+			}
+		}
+	}
+
+	*res = (*res)[:size]
+	return 0, false
+}
+
+func parsedParamToken(n *node, val string) Token {
+	return Token{
+		Mode:  PARAMETER_PARSED,
+		Len:   len(val),
+		Raw:   val,
+		Param: &n.Token,
+	}
+}
+
+// acMatcher is the Aho–Corasick automaton for multi-string search.
+type acMatcher struct {
+	next []map[byte]int
+	fail []int
+	// indices of keywords ending in the state
+	out      [][]int
+	keywords []string
+}
+
+func newACMatcher(keywords []string) *acMatcher {
+	m := &acMatcher{
+		next:     []map[byte]int{{}},
+		fail:     []int{0},
+		out:      [][]int{nil},
+		keywords: keywords,
+	}
+
+	// builds the prefix tree of keywords
+	for idx, keyword := range keywords {
+		state := 0
+		for i := 0; i < len(keyword); i++ {
+			nextState, ok := m.next[state][keyword[i]]
+			if !ok {
+				nextState = len(m.next)
+				m.next = append(m.next, map[byte]int{})
+				m.fail = append(m.fail, 0)
+				m.out = append(m.out, nil)
+				m.next[state][keyword[i]] = nextState
+			}
+			state = nextState
+		}
+		if len(keyword) > 0 {
+			m.out[state] = append(m.out[state], idx)
+		}
+	}
+
+	// builds the failure links (BFS)
+	queue := []int{}
+	for _, state := range m.next[0] {
+		queue = append(queue, state)
+	}
+	for len(queue) > 0 {
+		state := queue[0]
+		queue = queue[1:]
+		for char, nextState := range m.next[state] {
+			queue = append(queue, nextState)
+
+			fail := m.fail[state]
+			for {
+				if to, ok := m.next[fail][char]; ok {
+					m.fail[nextState] = to
+					break
+				}
+				if fail == 0 {
+					break
+				}
+				fail = m.fail[fail]
+			}
+			m.out[nextState] = append(m.out[nextState], m.out[m.fail[nextState]]...)
+		}
+	}
+
+	return m
+}
+
+// each calls fn for every occurrence of every keyword in the text (including overlapping).
+func (m *acMatcher) each(text string, fn func(start, keyword int)) {
+	state := 0
+	for i := 0; i < len(text); i++ {
+		for {
+			if to, ok := m.next[state][text[i]]; ok {
+				state = to
+				break
+			}
+			if state == 0 {
+				break
+			}
+			state = m.fail[state]
+		}
+		for _, keyword := range m.out[state] {
+			fn(i+1-len(m.keywords[keyword]), keyword)
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package strparam
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStore_Scan(t *testing.T) {
+	tests := []struct {
+		namedPatterns [][]string
+		text          string
+		want          []Occurrence
+	}{
+		{[][]string{}, "foo", nil},
+		{[][]string{{"a", "foo"}}, "", nil},
+		{[][]string{{"a", "foo"}}, "bar", nil},
+		{[][]string{{"a", "foo"}}, "1foo2foo", []Occurrence{
+			{Name: "a", Start: 1, End: 4, Params: Params{}},
+			{Name: "a", Start: 5, End: 8, Params: Params{}},
+		}},
+
+		{[][]string{
+			{"order", "order={id}, amount={amt};"},
+			{"user", "user={name};"},
+		}, "hi user=bob; order=1, amount=10; user=日本語;", []Occurrence{
+			{Name: "user", Start: 3, End: 12, Params: Params{{"name", "bob"}}},
+			{Name: "order", Start: 13, End: 32, Params: Params{{"id", "1"}, {"amt", "10"}}},
+			{Name: "user", Start: 33, End: 48, Params: Params{{"name", "日本語"}}},
+		}},
+
+		// shared prefix, the longer constant wins
+		{[][]string{
+			{"short", "id={id};"},
+			{"long", "id=x{id};"},
+		}, "id=x1; id=2;", []Occurrence{
+			{Name: "long", Start: 0, End: 6, Params: Params{{"id", "1"}}},
+			{Name: "short", Start: 7, End: 12, Params: Params{{"id", "2"}}},
+		}},
+		// falls back to other branch if the first is not completed
+		{[][]string{
+			{"a", "id={id};"},
+			{"b", "id={id}."},
+		}, "id=1.", []Occurrence{
+			{Name: "b", Start: 0, End: 5, Params: Params{{"id", "1"}}},
+		}},
+		// the leftmost occurrence wins
+		{[][]string{
+			{"a", "bc"},
+			{"b", "abcd"},
+		}, "abcd", []Occurrence{
+			{Name: "b", Start: 0, End: 4, Params: Params{}},
+		}},
+		// overlapping keywords
+		{[][]string{
+			{"a", "she"},
+			{"b", "he{x}!"},
+		}, "ushe1!", []Occurrence{
+			{Name: "a", Start: 1, End: 4, Params: Params{}},
+		}},
+		{[][]string{
+			{"a", "she!"},
+			{"b", "he{x}!"},
+		}, "ushe1!", []Occurrence{
+			{Name: "b", Start: 2, End: 6, Params: Params{{"x", "1"}}},
+		}},
+
+		// leading and trailing parameters
+		{[][]string{
+			{"a", "{x}!"},
+		}, "foo!bar!", []Occurrence{
+			{Name: "a", Start: 0, End: 4, Params: Params{{"x", "foo"}}},
+			{Name: "a", Start: 4, End: 8, Params: Params{{"x", "bar"}}},
+		}},
+		{[][]string{
+			{"a", "id={x}"},
+		}, "1 id=2 id=3", []Occurrence{
+			{Name: "a", Start: 2, End: 11, Params: Params{{"x", "2 id=3"}}},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%q->%q", tt.namedPatterns, tt.text), func(t *testing.T) {
+			s := NewStore()
+			for _, rawPattern := range tt.namedPatterns {
+				_, err := s.AddNamed(rawPattern[0], rawPattern[1])
+				require.NoError(t, err)
+			}
+			t.Log("[INFO] storage structure", s.String())
+
+			require.EqualValues(t, tt.want, s.Scan(tt.text))
+		})
+	}
+}
+
+func TestStore_Scan_AddAfterScan(t *testing.T) {
+	s := NewStore()
+	s.AddNamed("a", "foo")
+	assert.Len(t, s.Scan("foo bar"), 1)
+	s.AddNamed("b", "bar")
+	assert.Len(t, s.Scan("foo bar"), 2)
+}
+
+func Test_acMatcher(t *testing.T) {
+	m := newACMatcher([]string{"he", "she", "his", "hers"})
+	type hit struct{ start, keyword int }
+	var hits []hit
+	m.each("ushers", func(start, keyword int) {
+		hits = append(hits, hit{start, keyword})
+	})
+	assert.EqualValues(t, []hit{{1, 1}, {2, 0}, {2, 3}}, hits)
+}
+
+func Benchmark_Store_Scan_102(b *testing.B) {
+	r := NewStore()
+	for i := 0; i < 100; i++ {
+		r.Add(fmt.Sprintf("%s={p1}, %s={p2};", RandAZ(4), RandAZ(4)))
+	}
+	r.Add("order={id}, amount={amt};")
+	r.Add("user={name};")
+
+	text := "hello user=bob; and order=1, amount=10; and order=2, amount=20; bye"
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		r.Scan(text)
+	}
+}
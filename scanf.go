@@ -0,0 +1,308 @@
+package strparam
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// ErrNoMatch is returned by Scan if input string does not match to the pattern.
+var ErrNoMatch = errors.New("input does not match to the pattern")
+
+// ParseFormat returns the pattern from the format string of fmt.Sprintf (eg "user %s logged in from %s port %d").
+//
+// Each verb is a typed parameter named by its number (p1, p2, ...), %% is the constant %.
+// Supported verbs: %s %v (any value), %q (quoted string), %d (decimal integer),
+// %x %X %o %b (integer in base 16, 8 and 2), %f %F %e %E %g %G (float), %t (bool) and %c (single character).
+// Flags, width and precision are allowed (numbers are scanned without padding spaces,
+// with the flag # the prefix 0x, 0X, 0b or 0 of integer is optional), explicit argument indexes are not supported.
+// %c scanned into an integer (eg rune) is the code point of the character.
+//
+// Verbs should be separated by constants, the value of parameter ends at the first occurrence
+// of the next constant (same as Lookup).
+func ParseFormat(format string) (*Pattern, error) {
+	b := newPatternBuilder()
+	var verbs []formatVerb
+
+	for i := 0; i < len(format); {
+		next := strings.IndexByte(format[i:], '%')
+		if next < 0 {
+			b.addConst(format[i:])
+			break
+		}
+		b.addConst(format[i : i+next])
+		pos := i + next
+
+		// flags, width and precision
+		end := pos + 1
+		for end < len(format) && strings.IndexByte("+-# 0123456789.", format[end]) >= 0 {
+			end++
+		}
+		if end >= len(format) {
+			return nil, fmt.Errorf("missing verb, pos %d", pos)
+		}
+		verb, size := utf8.DecodeRuneInString(format[end:])
+		i = end + size
+
+		switch verb {
+		case '%':
+			if end != pos+1 {
+				return nil, fmt.Errorf("not supported flags of %%%%, pos %d", pos)
+			}
+			b.addConst("%")
+		case 's', 'v', 'q', 'd', 'x', 'X', 'o', 'b', 'f', 'F', 'e', 'E', 'g', 'G', 't', 'c':
+			if err := b.addParam(fmt.Sprintf("p%d", len(verbs)+1), false, pos); err != nil {
+				return nil, err
+			}
+			verbs = append(verbs, formatVerb{
+				verb:  verb,
+				sharp: strings.IndexByte(format[pos+1:end], '#') >= 0,
+			})
+		case '[':
+			return nil, fmt.Errorf("not supported explicit argument index, pos %d", pos)
+		default:
+			return nil, fmt.Errorf("not supported verb %q, pos %d", format[pos:i], pos)
+		}
+	}
+
+	p, err := b.pattern("")
+	if err != nil {
+		return nil, err
+	}
+	p.verbs = verbs
+	return p, nil
+}
+
+// Scan sets values of parameters to args positionally (a pointer per parameter), as fmt.Sscanf does.
+//
+// Values are checked by verbs of the format (see ParseFormat), for other patterns as by %v.
+// Supported types of args: pointers to string, []byte, bool, int*, uint*, float*
+// and same types as Params.Decode supports.
+//
+// ErrNoMatch is returned if input string does not match to the pattern.
+func (s *Pattern) Scan(in string, args ...interface{}) error {
+	if len(args) != s.NumParams {
+		return fmt.Errorf("expected %d arguments, got %d", s.NumParams, len(args))
+	}
+
+	found, params := s.Lookup(in)
+	if !found {
+		return ErrNoMatch
+	}
+
+	for i, param := range params {
+		verb := formatVerb{verb: 'v'}
+		if i < len(s.verbs) {
+			verb = s.verbs[i]
+		}
+		if err := scanValue(verb, param.Value, args[i]); err != nil {
+			return fmt.Errorf("argument %d (parameter %q, value %q): %v", i+1, param.Name, param.Value, err)
+		}
+	}
+	return nil
+}
+
+// formatVerb verb of parameter parsed from format string.
+type formatVerb struct {
+	verb rune
+	// flag # (alternate format)
+	sharp bool
+}
+
+// scanValue checks the value by verb and sets it to the pointer.
+//
+// nolint: gocyclo
+func scanValue(v formatVerb, value string, arg interface{}) error {
+	// checked before the fast path, the nil typed pointer (eg (*int)(nil)) panics on assignment
+	rv := reflect.ValueOf(arg)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return fmt.Errorf("expected non-nil pointer, got %T", arg)
+	}
+
+	base := 10
+	switch verb := v.verb; verb {
+	case 'q':
+		unquoted, err := strconv.Unquote(value)
+		if err != nil {
+			return errors.New("expected quoted string")
+		}
+		value = unquoted
+	case 'c':
+		if utf8.RuneCountInString(value) != 1 {
+			return errors.New("expected single character")
+		}
+		if isIntegerPtr(arg) {
+			// code point of the character (as fmt.Sscanf)
+			char, _ := utf8.DecodeRuneInString(value)
+			value = strconv.Itoa(int(char))
+		}
+	case 'd', 'x', 'X', 'o', 'b':
+		base = verbBase(verb)
+		value = strings.TrimSpace(value)
+		if v.sharp {
+			value = trimBasePrefix(value, verb)
+		}
+		if !isInteger(value, base) {
+			return fmt.Errorf("expected integer in base %d", base)
+		}
+	case 'f', 'F', 'e', 'E', 'g', 'G':
+		value = strings.TrimSpace(value)
+		if _, err := strconv.ParseFloat(value, 64); err != nil {
+			return errors.New("expected float")
+		}
+	case 't':
+		if _, err := strconv.ParseBool(value); err != nil {
+			return errors.New("expected bool")
+		}
+	}
+
+	// fast path for common types
+	switch dst := arg.(type) {
+	case *string:
+		*dst = value
+		return nil
+	case *[]byte:
+		*dst = []byte(value)
+		return nil
+	case *int:
+		n, err := strconv.ParseInt(value, base, strconv.IntSize)
+		if err != nil {
+			return err
+		}
+		*dst = int(n)
+		return nil
+	case *int64:
+		n, err := strconv.ParseInt(value, base, 64)
+		if err != nil {
+			return err
+		}
+		*dst = n
+		return nil
+	case *uint64:
+		n, err := strconv.ParseUint(value, base, 64)
+		if err != nil {
+			return err
+		}
+		*dst = n
+		return nil
+	case *float64:
+		n, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return err
+		}
+		*dst = n
+		return nil
+	case *bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		*dst = b
+		return nil
+	}
+
+	switch elem := rv.Elem(); elem.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if elem.Type() == durationType {
+			break
+		}
+		n, err := strconv.ParseInt(value, base, elem.Type().Bits())
+		if err != nil {
+			return err
+		}
+		elem.SetInt(n)
+		return nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(value, base, elem.Type().Bits())
+		if err != nil {
+			return err
+		}
+		elem.SetUint(n)
+		return nil
+	}
+
+	setter, err := newFieldSetter(rv.Elem().Type())
+	if err != nil {
+		return err
+	}
+	return setter(rv.Elem(), value)
+}
+
+func verbBase(verb rune) int {
+	switch verb {
+	case 'x', 'X':
+		return 16
+	case 'o':
+		return 8
+	case 'b':
+		return 2
+	}
+	return 10
+}
+
+// trimBasePrefix returns the integer without the prefix of base added by the flag # (after the sign).
+func trimBasePrefix(value string, verb rune) string {
+	sign := ""
+	if value != "" && (value[0] == '-' || value[0] == '+') {
+		sign, value = value[:1], value[1:]
+	}
+	switch verb {
+	case 'x', 'X':
+		if strings.HasPrefix(value, "0x") || strings.HasPrefix(value, "0X") {
+			value = value[2:]
+		}
+	case 'b':
+		if strings.HasPrefix(value, "0b") {
+			value = value[2:]
+		}
+	case 'o':
+		if len(value) > 1 && value[0] == '0' {
+			value = value[1:]
+		}
+	}
+	return sign + value
+}
+
+// isIntegerPtr returns true if the argument is pointer to integer.
+func isIntegerPtr(arg interface{}) bool {
+	rv := reflect.ValueOf(arg)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Type().Elem() == durationType {
+		return false
+	}
+	switch rv.Elem().Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+	}
+	return false
+}
+
+// isInteger returns true if the value is integer (with optional sign) in the base.
+func isInteger(value string, base int) bool {
+	if value != "" && (value[0] == '-' || value[0] == '+') {
+		value = value[1:]
+	}
+	if value == "" {
+		return false
+	}
+	for _, char := range value {
+		var digit int
+		switch {
+		case '0' <= char && char <= '9':
+			digit = int(char - '0')
+		case 'a' <= char && char <= 'f':
+			digit = int(char-'a') + 10
+		case 'A' <= char && char <= 'F':
+			digit = int(char-'A') + 10
+		default:
+			return false
+		}
+		if digit >= base {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,164 @@
+package strparam
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseFormat(t *testing.T) {
+	tests := []struct {
+		format  string
+		want    string
+		wantErr string
+	}{
+		{"user %s logged in from %s port %d", `START->Const("user ", len=5)->Param("{p1}")->Const(" logged in from ", len=16)->Param("{p2}")->Const(" port ", len=6)->Param("{p3}")->END`, ""},
+		{"%d%% done", `START->Param("{p1}")->Const("% done", len=6)->END`, ""},
+		{"took %.2fs", `START->Const("took ", len=5)->Param("{p1}")->Const("s", len=1)->END`, ""},
+		{"%s%d", "", "should be a pattern between the parameters, pos 2"},
+		{"done %", "", "missing verb, pos 5"},
+		{"%w", "", `not supported verb "%w", pos 0`},
+		{"%[1]d", "", "not supported explicit argument index, pos 0"},
+		{"%5%", "", "not supported flags of %%, pos 0"},
+		{"", "", "expression should not is empty"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			got, err := ParseFormat(tt.format)
+			if tt.wantErr != "" {
+				require.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got.String())
+		})
+	}
+}
+
+func TestPattern_Scan(t *testing.T) {
+	p, err := ParseFormat("user %s logged in from %s port %d")
+	require.NoError(t, err)
+
+	var (
+		user, ip string
+		port     int
+	)
+	require.NoError(t, p.Scan("user John Smith logged in from 10.0.0.1 port 22", &user, &ip, &port))
+	assert.Equal(t, "John Smith", user)
+	assert.Equal(t, "10.0.0.1", ip)
+	assert.Equal(t, 22, port)
+
+	require.Equal(t, ErrNoMatch, p.Scan("user bob logged out", &user, &ip, &port))
+	require.EqualError(t, p.Scan("user bob logged in from 10.0.0.1 port ssh", &user, &ip, &port),
+		`argument 3 (parameter "p3", value "ssh"): expected integer in base 10`)
+	require.EqualError(t, p.Scan("user bob logged in from 10.0.0.1 port 22", &user, &ip), "expected 3 arguments, got 2")
+	require.EqualError(t, p.Scan("user bob logged in from 10.0.0.1 port 22", &user, ip, &port),
+		`argument 2 (parameter "p2", value "10.0.0.1"): expected non-nil pointer, got string`)
+
+	// nil typed pointers of the fast path and of other types
+	require.EqualError(t, p.Scan("user bob logged in from 10.0.0.1 port 22", &user, &ip, (*int)(nil)),
+		`argument 3 (parameter "p3", value "22"): expected non-nil pointer, got *int`)
+	require.EqualError(t, p.Scan("user bob logged in from 10.0.0.1 port 22", (*string)(nil), &ip, &port),
+		`argument 1 (parameter "p1", value "bob"): expected non-nil pointer, got *string`)
+	require.EqualError(t, p.Scan("user bob logged in from 10.0.0.1 port 22", &user, &ip, (*int16)(nil)),
+		`argument 3 (parameter "p3", value "22"): expected non-nil pointer, got *int16`)
+	require.EqualError(t, p.Scan("user bob logged in from 10.0.0.1 port 22", &user, &ip, nil),
+		`argument 3 (parameter "p3", value "22"): expected non-nil pointer, got <nil>`)
+}
+
+func TestPattern_Scan_Verbs(t *testing.T) {
+	type custom int16
+
+	tests := []struct {
+		format  string
+		in      string
+		dst     interface{}
+		want    interface{}
+		wantErr string
+	}{
+		{"[%v]", "[a b]", new(string), "a b", ""},
+		{"[%q]", `["a\"b"]`, new(string), `a"b`, ""},
+		{"[%q]", `[a]`, new(string), "", "expected quoted string"},
+		{"[%5d]", "[   -12]", new(int64), int64(-12), ""},
+		{"[%d]", "[12]", new(custom), custom(12), ""},
+		{"[%d]", "[12]", new(string), "12", ""},
+		{"[%d]", "[1.5]", new(float64), 0.0, "expected integer in base 10"},
+		{"[%x]", "[ff]", new(uint8), uint8(255), ""},
+		{"[%x]", "[fff]", new(uint8), uint8(0), `strconv.ParseUint: parsing "fff": value out of range`},
+		{"[%b]", "[102]", new(int), 0, "expected integer in base 2"},
+		{"[%.2f]", "[3.14]", new(float64), 3.14, ""},
+		{"[%f]", "[pi]", new(float64), 0.0, "expected float"},
+		{"[%t]", "[true]", new(bool), true, ""},
+		{"[%t]", "[yes]", new(bool), false, "expected bool"},
+		{"[%c]", "[日]", new(string), "日", ""},
+		{"[%c]", "[ab]", new(string), "", "expected single character"},
+		{"[%c]", "[日]", new(rune), '日', ""},
+		{"[%c]", "[a]", new(byte), byte('a'), ""},
+		{"[%c]", "[a]", new(int), 97, ""},
+		{"[%c]", "[日]", new([]byte), []byte("日"), ""},
+		{"[%#x]", "[0xff]", new(int), 255, ""},
+		{"[%#X]", "[-0XFF]", new(int64), int64(-255), ""},
+		{"[%#x]", "[ff]", new(int), 255, ""},
+		{"[%#o]", "[017]", new(int), 15, ""},
+		{"[%#o]", "[0]", new(int), 0, ""},
+		{"[%#b]", "[0b101]", new(uint), uint(5), ""},
+		{"[%x]", "[0xff]", new(int), 0, "expected integer in base 16"},
+		{"[%s]", "[5s]", new(time.Duration), 5 * time.Second, ""},
+		{"[%s]", "[abc]", new([]byte), []byte("abc"), ""},
+		{"[%s]", "[abc]", new(struct{}), struct{}{}, "not supported type struct {}"},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%s->%s", tt.format, tt.in), func(t *testing.T) {
+			p, err := ParseFormat(tt.format)
+			require.NoError(t, err)
+			err = p.Scan(tt.in, tt.dst)
+			if tt.wantErr != "" {
+				require.EqualError(t, err, fmt.Sprintf("argument 1 (parameter \"p1\", value %q): %s", tt.in[1:len(tt.in)-1], tt.wantErr))
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, reflect.ValueOf(tt.dst).Elem().Interface())
+		})
+	}
+
+	// without verbs (as %v)
+	p, err := Parse("id={id}")
+	require.NoError(t, err)
+	var id uint
+	require.NoError(t, p.Scan("id=42", &id))
+	assert.Equal(t, uint(42), id)
+}
+
+func BenchmarkPattern_Scan(b *testing.B) {
+	p, err := ParseFormat("user %s logged in from %s port %d")
+	require.NoError(b, err)
+	var (
+		user, ip string
+		port     int
+	)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := p.Scan("user bob logged in from 10.0.0.1 port 22", &user, &ip, &port); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkSscanf(b *testing.B) {
+	var (
+		user, ip string
+		port     int
+	)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := fmt.Sscanf("user bob logged in from 10.0.0.1 port 22", "user %s logged in from %s port %d", &user, &ip, &port); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
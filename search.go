@@ -0,0 +1,173 @@
+package strparam
+
+import "strings"
+
+// Occurrence describes a single match of the pattern found inside a larger text.
+type Occurrence struct {
+	// Name of the matched pattern (by end token if sets).
+	Name string
+	// Start and End offsets (in bytes) of the match, text[Start:End].
+	Start, End int
+	Params     Params
+}
+
+// FindAll returns all non-overlapping occurrences of the pattern in the text.
+//
+// See FindAllIndex for details of the search.
+func (s *Pattern) FindAll(text string) []Occurrence {
+	locs := s.FindAllIndex(text, -1)
+	if locs == nil {
+		return nil
+	}
+
+	res := make([]Occurrence, 0, len(locs))
+	for _, loc := range locs {
+		res = append(res, Occurrence{
+			Name:   s.Name(),
+			Start:  loc[0],
+			End:    loc[1],
+			Params: s.paramsByLoc(text, loc[2:]),
+		})
+	}
+	return res
+}
+
+// FindAllIndex returns up to n (all if n < 0) non-overlapping occurrences of the pattern in the text.
+// Each occurrence is a list of offsets (in bytes) as in the regexp package:
+// start and end of the match and then start and end of each parameter.
+//
+// Unlike Lookup the pattern is not anchored at both ends of the text.
+// The search is leftmost and the parameters are lazy (as in Lookup):
+// - a leading parameter captures the text from the end of previous occurrence (or from begin of text)
+// - a trailing parameter captures the tail of text
+//
+// NOTE: nothing (empty list of tokens) not matches to anything.
+func (s *Pattern) FindAllIndex(text string, n int) [][]int {
+	tokens := s.matchableTokens()
+	if tokens == nil || n == 0 {
+		return nil
+	}
+
+	var res [][]int
+	var prevEnd int
+
+	for n < 0 || len(res) < n {
+		loc := searchTokens(tokens, text, prevEnd, 2+2*s.NumParams)
+		if loc == nil {
+			break
+		}
+		res = append(res, loc)
+
+		if loc[1] == loc[0] || loc[1] >= len(text) {
+			// nothing more can be found
+			break
+		}
+		prevEnd = loc[1]
+	}
+
+	return res
+}
+
+// searchTokens returns the offsets of the leftmost occurrence of the tokens in the text starting from offset.
+//
+// The first constant of tokens is used as an anchor of search.
+func searchTokens(tokens Tokens, text string, offset, sizeLoc int) []int {
+	if tokens[0].Mode == PARAMETER {
+		// leading parameter
+		if len(tokens) == 1 || tokens[1].Mode == END {
+			if !tokens[0].acceptsValue(text[offset:]) {
+				return nil
+			}
+			return []int{offset, len(text), offset, len(text)}
+		}
+
+		anchor := tokens[1]
+		for pos := offset; pos <= len(text); pos++ {
+			found := strings.Index(text[pos:], anchor.Raw)
+			if found < 0 {
+				return nil
+			}
+			pos += found
+			if !tokens[0].acceptsValue(text[offset:pos]) {
+				continue
+			}
+
+			loc := make([]int, 4, sizeLoc)
+			loc[0], loc[2], loc[3] = offset, offset, pos
+			if loc, end, ok := matchTokens(tokens[1:], text, pos, loc); ok {
+				loc[1] = end
+				return loc
+			}
+		}
+		return nil
+	}
+
+	anchor := tokens[0]
+	for pos := offset; pos <= len(text); pos++ {
+		found := strings.Index(text[pos:], anchor.Raw)
+		if found < 0 {
+			return nil
+		}
+		pos += found
+
+		loc := make([]int, 2, sizeLoc)
+		loc[0] = pos
+		if loc, end, ok := matchTokens(tokens, text, pos, loc); ok {
+			loc[1] = end
+			return loc
+		}
+	}
+	return nil
+}
+
+// matchTokens returns true if the tokens matches to the text from offset (not anchored at end of text).
+// Appends offsets of parameters to loc and returns end offset of match.
+func matchTokens(tokens Tokens, text string, offset int, loc []int) ([]int, int, bool) {
+	for num, t := range tokens {
+		switch t.Mode {
+		case START:
+		case END:
+			return loc, offset, true
+		case CONST, SEPARATOR:
+			if !strings.HasPrefix(text[offset:], t.Raw) {
+				return nil, 0, false
+			}
+			offset += t.Len
+		case PARAMETER:
+			if num+1 >= len(tokens) || tokens[num+1].Mode == END {
+				// trailing parameter captures the tail
+				if !t.acceptsValue(text[offset:]) {
+					return nil, 0, false
+				}
+				loc = append(loc, offset, len(text))
+				offset = len(text)
+				continue
+			}
+
+			_next := tokens[num+1]
+			if _next.Mode != CONST && _next.Mode != SEPARATOR {
+				return nil, 0, false
+			}
+			found := strings.Index(text[offset:], _next.Raw)
+			if found < 0 || !t.acceptsValue(text[offset:offset+found]) {
+				return nil, 0, false
+			}
+			loc = append(loc, offset, offset+found)
+			offset += found
+		default:
+			return nil, 0, false
+		}
+	}
+	return loc, offset, true
+}
+
+// paramNames returns names of parameters in order of appearance.
+func (s *Pattern) paramNames() []string {
+	res := make([]string, 0, s.NumParams)
+	for _, t := range s.Tokens {
+		if t.Mode == PARAMETER {
+			res = append(res, t.ParamName())
+		}
+	}
+	return res
+}
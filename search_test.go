@@ -0,0 +1,79 @@
+package strparam
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPattern_FindAll(t *testing.T) {
+	tests := []struct {
+		pattern string
+		text    string
+		want    []Occurrence
+	}{
+		{"foo", "", nil},
+		{"foo", "bar", nil},
+		{"foo", "foo", []Occurrence{{Start: 0, End: 3, Params: Params{}}}},
+		{"foo", "1foo2foo3", []Occurrence{{Start: 1, End: 4, Params: Params{}}, {Start: 5, End: 8, Params: Params{}}}},
+		{"aa", "aaaaa", []Occurrence{{Start: 0, End: 2, Params: Params{}}, {Start: 2, End: 4, Params: Params{}}}},
+
+		{"order={id}, amount={amt};", "hello order=1, amount=10; and order=2, amount=20; bye", []Occurrence{
+			{Start: 6, End: 25, Params: Params{{"id", "1"}, {"amt", "10"}}},
+			{Start: 30, End: 49, Params: Params{{"id", "2"}, {"amt", "20"}}},
+		}},
+		// the first candidate is not completed
+		{"order={id}, amount={amt};", "order=1 order=2, amount=20;", []Occurrence{
+			{Start: 0, End: 27, Params: Params{{"id", "1 order=2"}, {"amt", "20"}}},
+		}},
+		{"order={id};", "order=;order=日本語;", []Occurrence{
+			{Start: 0, End: 7, Params: Params{{"id", ""}}},
+			{Start: 7, End: 23, Params: Params{{"id", "日本語"}}},
+		}},
+		{"order={id};", "order=1", nil},
+
+		// trailing parameter captures the tail
+		{"id={id}", "a id=1 id=2", []Occurrence{
+			{Start: 2, End: 11, Params: Params{{"id", "1 id=2"}}},
+		}},
+		// leading parameter captures from the end of previous occurrence
+		{"{name}!", "foo!bar!", []Occurrence{
+			{Start: 0, End: 4, Params: Params{{"name", "foo"}}},
+			{Start: 4, End: 8, Params: Params{{"name", "bar"}}},
+		}},
+		{"{name}", "foo", []Occurrence{
+			{Start: 0, End: 3, Params: Params{{"name", "foo"}}},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%q->%q", tt.pattern, tt.text), func(t *testing.T) {
+			p, err := Parse(tt.pattern)
+			require.NoError(t, err)
+			got := p.FindAll(tt.text)
+			require.EqualValues(t, tt.want, got)
+			for _, item := range got {
+				found, params := p.Lookup(tt.text[item.Start:item.End])
+				if assert.True(t, found) {
+					assert.EqualValues(t, item.Params, params)
+				}
+			}
+		})
+	}
+}
+
+func TestPattern_FindAllIndex(t *testing.T) {
+	p, err := ParseWithName("order", "order={id}, amount={amt};")
+	require.NoError(t, err)
+	text := "order=1, amount=10; order=2, amount=20; order=3, amount=30;"
+
+	assert.Nil(t, p.FindAllIndex(text, 0))
+	assert.EqualValues(t, [][]int{{0, 19, 6, 7, 16, 18}}, p.FindAllIndex(text, 1))
+	assert.Len(t, p.FindAllIndex(text, 2), 2)
+	assert.Len(t, p.FindAllIndex(text, -1), 3)
+	assert.Equal(t, "order", p.FindAll(text)[0].Name)
+
+	assert.Nil(t, (*Pattern)(nil).FindAllIndex(text, -1))
+	assert.Nil(t, (&Pattern{Tokens: EmptySchema}).FindAllIndex(text, -1))
+}
@@ -7,15 +7,21 @@ import (
 	"sort"
 	"strings"
 	"sync"
+	"sync/atomic"
 
 	"github.com/pkg/errors"
 )
 
 // NewStore returns new storage instance for patterns.
 func NewStore() *Store {
+	return NewStoreWithDialect(DefaultDialect)
+}
+
+// NewStoreWithDialect returns new storage instance for patterns written in the syntax of dialect
+// (see Add and AddNamed).
+func NewStoreWithDialect(dialect Dialect) *Store {
 	return &Store{
-		root:       &node{Token: Token{}},
-		tokensPool: sync.Pool{},
+		dialect: dialect,
 	}
 }
 
@@ -27,48 +33,425 @@ func (r *Store) Add(exp string) (*Pattern, error) {
 }
 
 // AddNamed add named new pattern.
+//
+// Error is returned if parsing error or a pattern with the name already exists.
 func (r *Store) AddNamed(name, exp string) (*Pattern, error) {
 	return r.add(name, exp)
 }
 
+// AddWithValue returns parsed and added pattern from input value, the value v is returned by Match
+// for the pattern.
+//
+// Adding of the same pattern again replaces the value.
+// Error is returned if parsing error.
+func (r *Store) AddWithValue(exp string, v interface{}) (*Pattern, error) {
+	schema, err := r.parse("", exp)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.AddPatternWithValue(schema, v); err != nil {
+		return nil, err
+	}
+
+	return schema, nil
+}
+
+// AddWithPriority returns parsed and added pattern from input value with the priority.
+//
+// Among the patterns matching the same input (see FindAll) the pattern with higher priority wins
+// over the pattern found by the walk of tree (independent of the order of appends).
+// Patterns have priority 0 by default (added by Add).
+// Error is returned if parsing error.
+func (r *Store) AddWithPriority(exp string, priority int) (*Pattern, error) {
+	schema, err := r.parse("", exp)
+	if err != nil {
+		return nil, err
+	}
+	schema.Priority = priority
+
+	if err := r.AddPattern(schema); err != nil {
+		return nil, err
+	}
+
+	return schema, nil
+}
+
 // AddPattern add from pattern.
-func (r *Store) AddPattern(p *Pattern) {
-	if len(p.Tokens) > r.maxSize {
-		r.maxSize = len(p.Tokens)
-		r.tokensPool.New = func() interface{} { return make([]Token, 0, r.maxSize) }
+//
+// Error is returned same as AddPatternWithValue.
+func (r *Store) AddPattern(p *Pattern) error {
+	return r.AddPatternWithValue(p, nil)
+}
+
+// AddPatternWithValue add from pattern, the value v is returned by Match for the pattern.
+//
+// Adding of the same pattern again replaces the value.
+// Error is returned (the pattern is not added) if the pattern is invalid
+// or other pattern with the same name already exists (see AddNamed).
+func (r *Store) AddPatternWithValue(p *Pattern, v interface{}) error {
+	if err := checkPattern(p); err != nil {
+		return errors.Wrap(err, "invalid pattern")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	state, err := r.insert(r.load(), p, v)
+	if err != nil {
+		return err
+	}
+	r.state.Store(state)
+
+	return nil
+}
+
+// checkPattern returns error if the pattern can not be matched as built by Parse:
+// START, constants and parameters separated by constants, END.
+func checkPattern(p *Pattern) error {
+	if p == nil || len(p.Tokens) < 2 {
+		return errors.New("empty pattern")
+	}
+	if p.Tokens[0].Mode != START {
+		return errors.Errorf("unexpected token type %v at begin", p.Tokens[0].Mode)
+	}
+	if last := p.Tokens[len(p.Tokens)-1]; last.Mode != END {
+		return errors.Errorf("unexpected token type %v at end", last.Mode)
 	}
 
-	appendChild(r.root, 0, p.Tokens)
+	numParams := 0
+	for i, t := range p.Tokens[1 : len(p.Tokens)-1] {
+		switch t.Mode {
+		case CONST, SEPARATOR:
+			if t.Len != len(t.Raw) {
+				return errors.Errorf("invalid length %d of token %v", t.Len, t.String())
+			}
+		case PARAMETER:
+			if p.Tokens[i].Mode == PARAMETER {
+				return errors.Errorf("should be a pattern between the parameters, token %d", i+1)
+			}
+			numParams++
+		default:
+			return errors.Errorf("unexpected token type %v, token %d", t.Mode, i+1)
+		}
+	}
+	if numParams != p.NumParams {
+		return errors.Errorf("number of parameters is %d, expected %d", numParams, p.NumParams)
+	}
+	return nil
 }
 
 func (r *Store) add(name, exp string) (*Pattern, error) {
-	schema, err := ParseWithName(name, exp)
+	schema, err := r.parse(name, exp)
 	if err != nil {
-		return nil, errors.Wrap(err, "failed parse")
+		return nil, err
 	}
 
-	r.AddPattern(schema)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// the same named pattern again is an error too (there is no value to replace)
+	if name != "" && r.names[name] > 0 {
+		return nil, errors.Errorf("pattern with name %q already exists", name)
+	}
+	state, err := r.insert(r.load(), schema, nil)
+	if err != nil {
+		return nil, err
+	}
+	r.state.Store(state)
 
 	return schema, nil
 }
 
+// insert returns the new version of tree with added pattern (should be called under the lock,
+// the state is not changed).
+//
+// Error is returned if other pattern with the same name already exists
+// (the same pattern again only replaces the value).
+func (r *Store) insert(state *storeState, p *Pattern, v interface{}) (*storeState, error) {
+	maxSize := state.maxSize
+	if len(p.Tokens) > maxSize {
+		maxSize = len(p.Tokens)
+	}
+	numPatterns := state.numPatterns
+	if len(p.Tokens) > 0 && p.Tokens[len(p.Tokens)-1].Mode == END && !hasPath(state.root, p.Tokens) {
+		if name := p.Name(); name != "" && r.names[name] > 0 {
+			return nil, errors.Errorf("pattern with name %q already exists", name)
+		}
+		numPatterns++
+		r.countName(p.Name(), 1)
+	}
+
+	return &storeState{
+		root:        insertChild(state.root, p.Tokens, p, v),
+		maxSize:     maxSize,
+		numPatterns: numPatterns,
+	}, nil
+}
+
+// countName changes the number of patterns with the name (should be called under the lock).
+func (r *Store) countName(name string, delta int) {
+	if name == "" {
+		return
+	}
+	if r.names == nil {
+		r.names = map[string]int{}
+	}
+	r.names[name] += delta
+	if r.names[name] <= 0 {
+		delete(r.names, name)
+	}
+}
+
+// Remove removes the unnamed pattern (added by Add) parsed from input value.
+//
+// Returns false if the pattern does not exist. Error is returned if parsing error.
+func (r *Store) Remove(exp string) (bool, error) {
+	p, err := r.parse("", exp)
+	if err != nil {
+		return false, err
+	}
+	return r.RemovePattern(p), nil
+}
+
+// RemoveNamed removes all patterns with the name.
+//
+// Returns false if patterns with the name do not exist or the name is empty
+// (use Remove for unnamed patterns).
+func (r *Store) RemoveNamed(name string) bool {
+	if name == "" {
+		return false
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	state, removed := r.withoutNamed(r.load(), name)
+	if removed {
+		r.state.Store(state)
+	}
+	return removed
+}
+
+// RemovePattern removes the pattern (same tokens including the name).
+//
+// Returns false if the pattern does not exist.
+func (r *Store) RemovePattern(p *Pattern) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	state, removed := r.without(r.load(), p.Tokens)
+	if removed {
+		r.state.Store(state)
+	}
+	return removed
+}
+
+// Replace replaces patterns with the name by new pattern parsed from input value.
+//
+// Error is returned if parsing error (the storage is not changed), the name is empty
+// or patterns with the name do not exist. Values of the replaced patterns are not kept.
+//
+// Replacing is atomic: readers see either the old patterns or the new one.
+func (r *Store) Replace(name, exp string) (*Pattern, error) {
+	if name == "" {
+		return nil, errors.New("name of pattern is empty")
+	}
+	p, err := r.parse(name, exp)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	state, removed := r.withoutNamed(r.load(), name)
+	if !removed {
+		return nil, errors.Errorf("pattern %q not found", name)
+	}
+	state, err = r.insert(state, p, nil)
+	if err != nil {
+		return nil, err
+	}
+	r.state.Store(state)
+	return p, nil
+}
+
+// withoutNamed returns the new version of tree without patterns with the name
+// (should be called under the lock, the state is not changed).
+func (r *Store) withoutNamed(state *storeState, name string) (*storeState, bool) {
+	var found []Tokens
+	walkPatterns(state.root, func(p *Pattern) bool {
+		if p.Name() == name {
+			found = append(found, p.Tokens)
+		}
+		return true
+	})
+	for _, tokens := range found {
+		state, _ = r.without(state, tokens)
+	}
+	return state, len(found) > 0
+}
+
+// without returns the new version of tree without END node of the pattern, empty nodes are pruned
+// (should be called under the lock, the state is not changed).
+func (r *Store) without(state *storeState, tokens Tokens) (*storeState, bool) {
+	root, removed := withoutChild(state.root, tokens)
+	if !removed {
+		return state, false
+	}
+	if root == nil {
+		root = &node{}
+	}
+	r.countName(tokens[len(tokens)-1].Raw, -1)
+
+	return &storeState{
+		root:        root,
+		maxSize:     maxDepth(root, 0),
+		numPatterns: state.numPatterns - 1,
+	}, true
+}
+
+// maxDepth returns the max number of tokens of patterns in branch.
+func maxDepth(n *node, level int) int {
+	res := 0
+	if n.Token.Mode == END {
+		res = level
+	}
+	for _, child := range n.Childs {
+		if depth := maxDepth(child, level+1); depth > res {
+			res = depth
+		}
+	}
+	return res
+}
+
+// parse returns pattern parsed by dialect of storage.
+func (r *Store) parse(name, exp string) (*Pattern, error) {
+	parse := r.dialect
+	if parse == nil {
+		parse = DefaultDialect
+	}
+	p, err := parse(name, exp)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed parse")
+	}
+	return p, nil
+}
+
 // Find returns full pattern matched for incoming string.
 func (r *Store) Find(in string) *Pattern {
-	tokens := r.getlistTokens()
+	state := r.load()
+	buf := r.getlistTokens(state.maxSize)
+	defer r.putlistTokens(buf)
 	numParams := 0
 
-	lookupNextToken(in, 0, r.root, &tokens, &numParams)
-	defer r.putlistTokens(tokens)
+	lookup(in, state.root, buf, &numParams, nil)
+	tokens := *buf
 
 	if len(tokens) <= 2 || tokens[0].Mode != START || tokens[len(tokens)-1].Mode != END {
 		// not a complete pattern
 		return nil
 	}
 
-	return &Pattern{Tokens: tokens, NumParams: numParams}
+	// copy because the list of tokens returns to the pool
+	res := make(Tokens, len(tokens))
+	copy(res, tokens)
+
+	return &Pattern{Tokens: res, NumParams: numParams}
+}
+
+// Match result of matching by Store.Match.
+type Match struct {
+	// Name name of pattern (empty for unnamed pattern).
+	Name string
+	// Pattern the registered pattern (as added to the storage).
+	Pattern *Pattern
+	// Params values of parameters.
+	Params Params
+	// Value the value of pattern (see AddWithValue).
+	Value interface{}
 }
 
-func lookupNextToken(in string, offset int, parent *node, res *[]Token, numParams *int) {
+// Match returns the matched pattern and values of parameters for incoming string.
+//
+// Same as Find and then Lookup by found pattern, but in a single walk of the tree.
+func (r *Store) Match(in string) (Match, bool) {
+	state := r.load()
+	buf := r.getlistTokens(state.maxSize)
+	defer r.putlistTokens(buf)
+	numParams := 0
+
+	end := lookup(in, state.root, buf, &numParams, nil)
+	tokens := *buf
+
+	if end == nil || len(tokens) <= 2 || tokens[0].Mode != START {
+		// not a complete pattern
+		return Match{}, false
+	}
+
+	var params Params
+	if numParams > 0 {
+		params = make(Params, 0, numParams)
+	}
+	for _, t := range tokens {
+		if t.Mode == PARAMETER_PARSED {
+			params = append(params, Param{Name: t.ParamName(), Value: t.Raw})
+		}
+	}
+
+	return Match{
+		Name:    end.Token.Raw,
+		Pattern: end.pattern,
+		Params:  params,
+		Value:   end.value,
+	}, true
+}
+
+// lookup walks the tree by input string (see lookupNextToken) and returns the END node of found pattern
+// (nil if not found), the tokens of the path of found pattern are appended to res.
+//
+// Priority is applied among the patterns matched to the input (as by FindAll): the pattern with
+// higher priority than of the found one (than 0 if not found) wins, the first in order of tree among equal.
+func lookup(in string, root *node, res *[]Token, numParams *int, tr *Trace) *node {
+	var end *node
+	lookupNextToken(in, 0, root, res, numParams, &end, tr)
+
+	best := 0
+	if end != nil {
+		best = end.weight
+	}
+	if root.weight <= best {
+		// there are no patterns with higher priority
+		return end
+	}
+
+	var tokens Tokens
+	var prioritized *node
+	walkAll(in, 0, root, &tokens, func(n *node) bool {
+		// the weight of node is max priority of patterns of the branch
+		return n.weight <= best
+	}, func(found *node) {
+		best, prioritized = found.weight, found
+		*res = append((*res)[:0], tokens...)
+	})
+	if prioritized == nil {
+		return end
+	}
+
+	*numParams = 0
+	for _, t := range *res {
+		if t.Mode == PARAMETER_PARSED {
+			*numParams++
+		}
+	}
+	tr.setPrioritized()
+	return prioritized
+}
+
+// lookupNextToken walks the tree by input string and appends the tokens of matched path.
+// Sets end to the reached END node. Visited nodes are recorded to the trace (if not nil).
+func lookupNextToken(in string, offset int, parent *node, res *[]Token, numParams *int, end **node, tr *Trace) {
 	// if offset >= len(in) {
 	// 	log.Printf("Offset %d has gone out of bounds (or is equal) of the incoming string (len=%d).\n", offset, len(in))
 	// 	return
@@ -94,10 +477,11 @@ func lookupNextToken(in string, offset int, parent *node, res *[]Token, numParam
 			// -- -- -- {END}
 			// -- -- {END}
 
+			tr.add(len(*res), child.Token, offset, StepMatched)
 			*res = append(*res, child.Token)
 
 			// jump into the branch
-			lookupNextToken(in, offset, child, res, numParams)
+			lookupNextToken(in, offset, child, res, numParams, end, tr)
 
 			// returns because must be onece start token
 			return
@@ -106,10 +490,13 @@ func lookupNextToken(in string, offset int, parent *node, res *[]Token, numParam
 			// only if the offset is strictly equal to the input string
 			if len(in) == offset {
 				// if we have reached the END type token, then we have completely specific pattern
+				tr.add(len(*res), child.Token, offset, StepMatched)
 				*res = append(*res, child.Token)
+				*end = child
 				// returns because have reached the end
 				return
 			}
+			tr.add(len(*res), child.Token, offset, StepEndLeftover)
 		case CONST, SEPARATOR:
 			// general case
 			//
@@ -122,27 +509,35 @@ func lookupNextToken(in string, offset int, parent *node, res *[]Token, numParam
 			if offset+child.Token.Len <= len(in) {
 				// if the next token is END then the tail must match exactly
 				if child.nextSingleEnd() && offset+child.Token.Len != len(in) {
+					tr.add(len(*res), child.Token, offset, StepConstNotAtEnd)
 					continue
 				}
 				if in[offset:offset+child.Token.Len] == child.Token.Raw {
 					if offset+child.Token.Len == len(in) {
 						// end of the list
+						tr.add(len(*res), child.Token, offset, StepMatched)
 						*res = append(*res, child.Token)
-						lookupNextToken(in, offset+child.Token.Len, child, res, numParams)
+						lookupNextToken(in, offset+child.Token.Len, child, res, numParams, end, tr)
 						return
 					}
 
 					if child.nextHas(PARAMETER) || child.nextPrefixMatch(in[offset+child.Token.Len:]) {
 						// childs has match token
 
+						tr.add(len(*res), child.Token, offset, StepMatched)
 						*res = append(*res, child.Token)
 						// next params
-						lookupNextToken(in, offset+child.Token.Len, child, res, numParams)
+						lookupNextToken(in, offset+child.Token.Len, child, res, numParams, end, tr)
 						// returns because we move deeper into the tree
 						return
 					}
 
+					tr.add(len(*res), child.Token, offset, StepNextPrefixMismatch)
+				} else {
+					tr.add(len(*res), child.Token, offset, StepConstMismatch)
 				}
+			} else {
+				tr.add(len(*res), child.Token, offset, StepConstOutOfInput)
 			}
 
 		case PARAMETER:
@@ -153,7 +548,8 @@ func lookupNextToken(in string, offset int, parent *node, res *[]Token, numParam
 			// -- -- {END}
 
 			// looking for the next node to understand when the parameter ends
-			nextNode, addOffset := rightPath(in, offset, child)
+			step := tr.add(len(*res), child.Token, offset, StepParamUnterminated)
+			nextNode, addOffset := rightPath(in, offset, child, tr, len(*res)+1)
 
 			if nextNode != nil && len(in) >= offset+addOffset {
 				// if offset+addOffset+nextNode.Token.Len > len(in) {
@@ -161,8 +557,14 @@ func lookupNextToken(in string, offset int, parent *node, res *[]Token, numParam
 				// }
 
 				if len(parent.Childs)-1 > idx && addOffset == 0 {
+					tr.set(step, StepEmptyParamSkipped, "")
 					continue
 				}
+				if !child.Token.acceptsValue(in[offset : offset+addOffset]) {
+					tr.set(step, StepParamNotSegment, in[offset:offset+addOffset])
+					continue
+				}
+				tr.set(step, StepMatched, in[offset:offset+addOffset])
 
 				*res = append(*res, Token{
 					Mode:  PARAMETER_PARSED,
@@ -174,9 +576,12 @@ func lookupNextToken(in string, offset int, parent *node, res *[]Token, numParam
 
 				// added const or END token (that after the parameter)
 				*res = append(*res, nextNode.Token)
+				if nextNode.Token.Mode == END {
+					*end = nextNode
+				}
 
 				// jump to found const token
-				lookupNextToken(in, offset+addOffset+nextNode.Token.Len, nextNode, res, numParams)
+				lookupNextToken(in, offset+addOffset+nextNode.Token.Len, nextNode, res, numParams, end, tr)
 
 				// returns because we move deeper into the tree from found matched pattern
 				return
@@ -193,7 +598,9 @@ func lookupNextToken(in string, offset int, parent *node, res *[]Token, numParam
 	}
 }
 
-func rightPath(in string, offset int, node *node) (*node, int) {
+// rightPath returns the node after the parameter (the first constant found in the rest of input or END)
+// and the length of value of parameter. Visited nodes are recorded to the trace (if not nil).
+func rightPath(in string, offset int, node *node, tr *Trace, depth int) (*node, int) {
 	for _, child := range node.Childs {
 		switch child.Token.Mode {
 		case PARAMETER:
@@ -221,10 +628,13 @@ func rightPath(in string, offset int, node *node) (*node, int) {
 			// -- -- {CONST}
 			// -- -- {END}
 			if found := strings.Index(in[offset:], child.Token.Raw); found > -1 {
+				tr.add(depth, child.Token, offset+found, StepMatched)
 				return child, found
 			}
+			tr.add(depth, child.Token, offset, StepNextConstNotFound)
 		case END:
 			// returns tail
+			tr.add(depth, child.Token, len(in), StepMatched)
 			return child, len(in) - offset
 		default:
 			panic(fmt.Errorf("not expected node type %q", child.Token.Mode.String()))
@@ -233,38 +643,134 @@ func rightPath(in string, offset int, node *node) (*node, int) {
 	return nil, 0
 }
 
-// TODO: cover with tests as the tree is filled
-func appendChild(parent *node, i int, tokens []Token) {
-	if i >= len(tokens) {
-		return
+// insertChild returns copy of the parent node with added branch of tokens (the parent is not changed).
+// The END node of the branch refers to the pattern and the value (replaced for existing END node).
+//
+// Only nodes of the path are copied, other nodes are shared with the previous tree.
+func insertChild(parent *node, tokens []Token, p *Pattern, v interface{}) *node {
+	if len(tokens) == 0 {
+		if parent.Token.Mode != END {
+			return parent
+		}
+		res := parent.copy()
+		res.pattern, res.value = p, v
+		res.updateWeight()
+		return res
 	}
 
-	for _, node := range parent.Childs {
-		if node.Token.Equal(tokens[i]) {
-			appendChild(node, i+1, tokens)
-			return
+	res := parent.copy()
+	for i, child := range res.Childs {
+		if child.Token.Equal(tokens[0]) {
+			res.Childs[i] = insertChild(child, tokens[1:], p, v)
+			// the priority and childs of branch are changed, sorting of siblings too
+			res.updateWeight()
+			sort.Sort(res)
+			return res
+		}
+	}
+
+	newNode := &node{Token: tokens[0]}
+	res.Childs = append(res.Childs, insertChild(newNode, tokens[1:], p, v))
+	res.updateWeight()
+	sort.Sort(res)
+
+	return res
+}
+
+// hasPath returns true if the tree has the branch of tokens.
+func hasPath(n *node, tokens []Token) bool {
+	for _, token := range tokens {
+		var next *node
+		for _, child := range n.Childs {
+			if child.Token.Equal(token) {
+				next = child
+				break
+			}
+		}
+		if next == nil {
+			return false
 		}
+		n = next
+	}
+	return n.Token.Mode == END
+}
+
+// withoutChild returns copy of the node without END node of the branch of tokens
+// (nil if the node has no more childs). The node is not changed.
+func withoutChild(n *node, tokens []Token) (*node, bool) {
+	if len(tokens) == 0 {
+		return nil, n.Token.Mode == END
 	}
 
-	newNode := &node{Token: tokens[i]}
-	parent.Childs = append(parent.Childs, newNode)
-	appendChild(newNode, i+1, tokens)
+	for i, child := range n.Childs {
+		if !child.Token.Equal(tokens[0]) {
+			continue
+		}
+		newChild, removed := withoutChild(child, tokens[1:])
+		if !removed {
+			return n, false
+		}
+
+		res := n.copy()
+		if newChild == nil {
+			// prunes empty branch
+			res.Childs = append(res.Childs[:i], res.Childs[i+1:]...)
+		} else {
+			res.Childs[i] = newChild
+		}
+		if len(res.Childs) == 0 {
+			return nil, true
+		}
+		// the number of childs is changed, sorting of siblings too
+		res.updateWeight()
+		sort.Sort(res)
+		return res, true
+	}
 
-	sort.Sort(parent)
+	return n, false
 }
 
 // Store this is patterns repository.
+//
+// Store is safe for concurrent use. Readers never block: the tree is immutable,
+// writers (serialized) build a new version of the tree (copying only the changed path)
+// and replace the current one atomically.
 type Store struct {
+	// current version of tree (*storeState)
+	state atomic.Value
+	// serializes writers
+	mu         sync.Mutex
+	tokensPool sync.Pool
+	// parser of patterns
+	dialect Dialect
+	// number of patterns by name (guarded by mu)
+	names map[string]int
+}
+
+// storeState immutable version of tree.
+type storeState struct {
 	root *node
 	// max size slice of tokens for all patterns
-	maxSize    int
-	tokensPool sync.Pool
+	maxSize int
+	// number of patterns (END nodes)
+	numPatterns int
+	// lazily built helper for Scan
+	scannerOnce sync.Once
+	scanner     *scanner
+}
+
+// load returns the current version of tree.
+func (r *Store) load() *storeState {
+	if state, ok := r.state.Load().(*storeState); ok {
+		return state
+	}
+	return &storeState{root: &node{}}
 }
 
 // String returns the patent storage schema as a tree.
 func (s *Store) String() string {
 	res := new(bytes.Buffer)
-	printChilds(res, 0, s.root)
+	printChilds(res, 0, s.load().root)
 	return res.String()
 }
 
@@ -280,6 +786,11 @@ func printChilds(w io.Writer, level int, n *node) {
 type node struct {
 	Token  Token
 	Childs []*node
+	// registered pattern and its value (for END node)
+	pattern *Pattern
+	value   interface{}
+	// max priority of patterns of the branch
+	weight int
 }
 
 // // isOneEndChild reutrns true if the current branch has END
@@ -290,6 +801,30 @@ type node struct {
 // 	return false
 // }
 
+// copy returns copy of the node (childs are shared).
+func (n *node) copy() *node {
+	childs := make([]*node, len(n.Childs), len(n.Childs)+1)
+	copy(childs, n.Childs)
+	return &node{Token: n.Token, Childs: childs, pattern: n.pattern, value: n.value, weight: n.weight}
+}
+
+// updateWeight sets the weight of node by the priority of pattern (for END node) or by the childs.
+func (n *node) updateWeight() {
+	if n.Token.Mode == END {
+		n.weight = 0
+		if n.pattern != nil {
+			n.weight = n.pattern.Priority
+		}
+		return
+	}
+	n.weight = 0
+	for i, child := range n.Childs {
+		if i == 0 || child.weight > n.weight {
+			n.weight = child.weight
+		}
+	}
+}
+
 // Len returns the number of children.
 func (n *node) Len() int {
 	return len(n.Childs)
@@ -338,20 +873,59 @@ func (n *node) nextPrefixMatch(offseted string) bool {
 	return false
 }
 
-// Less returns true if
-// - left token type is CONST
-// - more length of value of token (type is CONST) on the left than right
-// - more num of children on the left than right
+// Less returns true if the left node is more specific than the right one (total order of siblings):
+// - by kind of token: constant (CONST and SEPARATOR), END, parameter followed by constant, catch-all parameter
+// - more length of value of constant
+// - parameter limited by segment of path (see SegmentParameterToken)
+// - more num of children
+// - by type and value of token (for equal specificity)
+//
+// So the order of siblings does not depend on the order of appends.
 func (n *node) Less(i, j int) bool {
-	if n.Childs[i].Token.Mode != n.Childs[j].Token.Mode {
-		if n.Childs[i].Token.Mode == CONST {
-			return true
-		}
+	a, b := n.Childs[i], n.Childs[j]
+	if a.specificityRank() != b.specificityRank() {
+		return a.specificityRank() < b.specificityRank()
+	}
+	if a.Token.Len != b.Token.Len && a.specificityRank() == rankConst {
+		return a.Token.Len > b.Token.Len
+	}
+	if a.Token.Segment != b.Token.Segment {
+		return a.Token.Segment
+	}
+	if len(a.Childs) != len(b.Childs) {
+		return len(a.Childs) > len(b.Childs)
+	}
+	if a.Token.Mode != b.Token.Mode {
+		return a.Token.Mode < b.Token.Mode
 	}
-	if n.Childs[i].lengthConstOrZero() != n.Childs[j].lengthConstOrZero() {
-		return n.Childs[i].lengthConstOrZero() >= n.Childs[j].lengthConstOrZero()
+	if a.Token.Raw != b.Token.Raw {
+		return a.Token.Raw < b.Token.Raw
+	}
+	return a.Token.Len < b.Token.Len
+}
+
+// ranks of specificity of nodes (the smaller is the more specific)
+const (
+	rankConst = iota
+	rankEnd
+	rankParam
+	rankCatchAll
+)
+
+// specificityRank returns the rank of node by kind of token.
+func (n *node) specificityRank() int {
+	switch n.Token.Mode {
+	case CONST, SEPARATOR:
+		return rankConst
+	case END:
+		return rankEnd
+	case PARAMETER:
+		if n.nextSingleEnd() {
+			return rankCatchAll
+		}
+		return rankParam
 	}
-	return len(n.Childs[i].Childs) >= len(n.Childs[j].Childs)
+	return rankCatchAll + 1
 }
 
 // Swap swap children
@@ -0,0 +1,277 @@
+package strparam
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/binary"
+	"hash/crc32"
+
+	"github.com/pkg/errors"
+)
+
+// binary format of storage
+//
+// magic (4 bytes) | version (1 byte) | payload | CRC-32 of previous bytes (4 bytes, big endian)
+//
+// payload: uvarint maxSize (not trusted on load, computed by the tree) | root node
+// node: uvarint mode | uvarint len | uvarint len(raw) | raw | varint weight | uvarint flags | uvarint num childs | childs...
+// flags: 1 - parameter is limited by segment of path (see SegmentParameterToken)
+//
+// version 1 has no weight of node (priorities of patterns are 0).
+// versions 1 and 2 have no flags of node.
+const (
+	storeBinaryMagic   = "SPST"
+	storeBinaryVersion = 3
+)
+
+// flags of node in the binary format
+const (
+	binaryFlagSegment = 1 << iota
+)
+
+// MarshalBinary implements encoding.BinaryMarshaler interface.
+//
+// Saves the sorted tree of patterns as is (including names of patterns).
+// Values of patterns (see AddWithValue) are not saved.
+func (r *Store) MarshalBinary() ([]byte, error) {
+	buf := new(bytes.Buffer)
+	buf.WriteString(storeBinaryMagic)
+	buf.WriteByte(storeBinaryVersion)
+
+	state := r.load()
+	writeUvarint(buf, uint64(state.maxSize))
+	writeNode(buf, state.root)
+
+	sum := make([]byte, 4)
+	binary.BigEndian.PutUint32(sum, crc32.ChecksumIEEE(buf.Bytes()))
+	buf.Write(sum)
+
+	return buf.Bytes(), nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler interface.
+//
+// Replaces all patterns of storage. The tree is loaded as is (without sorting).
+func (r *Store) UnmarshalBinary(data []byte) error {
+	if len(data) < len(storeBinaryMagic)+1+4 {
+		return errors.New("invalid data: too short")
+	}
+	if string(data[:len(storeBinaryMagic)]) != storeBinaryMagic {
+		return errors.New("invalid data: unknown format")
+	}
+	version := data[len(storeBinaryMagic)]
+	if version < 1 || version > storeBinaryVersion {
+		return errors.Errorf("invalid data: not supported version %d", version)
+	}
+
+	body, sum := data[:len(data)-4], data[len(data)-4:]
+	if crc32.ChecksumIEEE(body) != binary.BigEndian.Uint32(sum) {
+		return errors.New("invalid data: checksum mismatch")
+	}
+
+	reader := &binaryReader{data: body[len(storeBinaryMagic)+1:], version: version}
+	// max size of patterns is not trusted, it is computed by the tree
+	reader.uvarint()
+	root := reader.node(nil)
+	if reader.err != nil {
+		return errors.Wrap(reader.err, "invalid data")
+	}
+	if len(reader.data) > 0 {
+		return errors.New("invalid data: unexpected trailing bytes")
+	}
+
+	for _, child := range root.Childs {
+		attachPatterns(child, nil)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	numPatterns := 0
+	r.names = nil
+	walkPatterns(root, func(p *Pattern) bool {
+		numPatterns++
+		r.countName(p.Name(), 1)
+		return true
+	})
+	r.state.Store(&storeState{
+		root:        root,
+		maxSize:     maxDepth(root, 0),
+		numPatterns: numPatterns,
+	})
+
+	return nil
+}
+
+// attachPatterns sets patterns to END nodes of the branch (the pattern is the path from root).
+func attachPatterns(n *node, path Tokens) {
+	path = append(path, n.Token)
+	if n.Token.Mode == END {
+		n.pattern = &Pattern{Tokens: append(Tokens{}, path...), Priority: n.weight}
+		for _, t := range path {
+			if t.Mode == PARAMETER {
+				n.pattern.NumParams++
+			}
+		}
+	}
+	for _, child := range n.Childs {
+		attachPatterns(child, path)
+	}
+}
+
+func writeUvarint(buf *bytes.Buffer, v uint64) {
+	var tmp [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(tmp[:], v)
+	buf.Write(tmp[:n])
+}
+
+func writeVarint(buf *bytes.Buffer, v int64) {
+	var tmp [binary.MaxVarintLen64]byte
+	n := binary.PutVarint(tmp[:], v)
+	buf.Write(tmp[:n])
+}
+
+func writeNode(buf *bytes.Buffer, n *node) {
+	writeUvarint(buf, uint64(n.Token.Mode))
+	writeUvarint(buf, uint64(n.Token.Len))
+	writeUvarint(buf, uint64(len(n.Token.Raw)))
+	buf.WriteString(n.Token.Raw)
+	writeVarint(buf, int64(n.weight))
+	var flags uint64
+	if n.Token.Segment {
+		flags |= binaryFlagSegment
+	}
+	writeUvarint(buf, flags)
+	writeUvarint(buf, uint64(len(n.Childs)))
+	for _, child := range n.Childs {
+		writeNode(buf, child)
+	}
+}
+
+// binaryReader helper for reading the binary format (keeps the first error).
+type binaryReader struct {
+	data    []byte
+	version byte
+	err     error
+}
+
+func (r *binaryReader) uvarint() uint64 {
+	if r.err != nil {
+		return 0
+	}
+	v, n := binary.Uvarint(r.data)
+	if n <= 0 {
+		r.err = errors.New("failed read number")
+		return 0
+	}
+	r.data = r.data[n:]
+	return v
+}
+
+func (r *binaryReader) varint() int64 {
+	if r.err != nil {
+		return 0
+	}
+	v, n := binary.Varint(r.data)
+	if n <= 0 {
+		r.err = errors.New("failed read number")
+		return 0
+	}
+	r.data = r.data[n:]
+	return v
+}
+
+func (r *binaryReader) string() string {
+	size := r.uvarint()
+	if r.err != nil {
+		return ""
+	}
+	if uint64(len(r.data)) < size {
+		r.err = errors.New("unexpected end of data")
+		return ""
+	}
+	v := string(r.data[:size])
+	r.data = r.data[size:]
+	return v
+}
+
+// node reads the node and its childs, parent is nil for the root.
+//
+// The structure of tree is checked as built by Store: the root is the only UNKNOWN node,
+// START is the child of the root, END has no childs, parameters are separated by constants,
+// length of constant is the length of its value.
+func (r *binaryReader) node(parent *node) *node {
+	n := &node{
+		Token: Token{
+			Mode: TokenMode(r.uvarint()),
+			Len:  int(r.uvarint()),
+			Raw:  r.string(),
+		},
+	}
+	if r.version >= 2 {
+		n.weight = int(r.varint())
+	}
+	var flags uint64
+	if r.version >= 3 {
+		flags = r.uvarint()
+	}
+	numChilds := r.uvarint()
+	if r.err != nil {
+		return nil
+	}
+	switch n.Token.Mode {
+	case UNKNOWN_TokenMode, CONST, SEPARATOR, PARAMETER, START, END:
+	default:
+		r.err = errors.Errorf("not supported token type %v", n.Token.Mode)
+		return nil
+	}
+	if flags&^binaryFlagSegment != 0 || flags&binaryFlagSegment != 0 && n.Token.Mode != PARAMETER {
+		r.err = errors.Errorf("invalid flags %d of token %v", flags, n.Token.String())
+		return nil
+	}
+	n.Token.Segment = flags&binaryFlagSegment != 0
+	if err := checkBinaryNode(parent, n, numChilds); err != nil {
+		r.err = err
+		return nil
+	}
+	// each node takes at least 4 bytes
+	if numChilds > uint64(len(r.data))/4 {
+		r.err = errors.New("invalid number of childs")
+		return nil
+	}
+	if numChilds > 0 {
+		n.Childs = make([]*node, 0, numChilds)
+	}
+	for i := uint64(0); i < numChilds; i++ {
+		child := r.node(n)
+		if r.err != nil {
+			return nil
+		}
+		n.Childs = append(n.Childs, child)
+	}
+	return n
+}
+
+// checkBinaryNode returns error if the node can not be at the place of tree (parent is nil for the root).
+func checkBinaryNode(parent, n *node, numChilds uint64) error {
+	switch {
+	case parent == nil && n.Token.Mode != UNKNOWN_TokenMode:
+		return errors.Errorf("unexpected token type %v of root", n.Token.Mode)
+	case parent != nil && n.Token.Mode == UNKNOWN_TokenMode:
+		return errors.New("unexpected root token in branch")
+	case n.Token.Mode == START && parent.Token.Mode != UNKNOWN_TokenMode:
+		return errors.Errorf("unexpected token type %v after %v", n.Token.Mode, parent.Token.Mode)
+	case n.Token.Mode == PARAMETER && parent.Token.Mode == PARAMETER:
+		return errors.Errorf("unexpected token type %v after %v", n.Token.Mode, parent.Token.Mode)
+	case n.Token.Mode == END && numChilds > 0:
+		return errors.Errorf("unexpected childs of token type %v", n.Token.Mode)
+	case (n.Token.Mode == CONST || n.Token.Mode == SEPARATOR) && n.Token.Len != len(n.Token.Raw):
+		return errors.Errorf("invalid length %d of token %v", n.Token.Len, n.Token.String())
+	}
+	return nil
+}
+
+var (
+	_ encoding.BinaryMarshaler   = (*Store)(nil)
+	_ encoding.BinaryUnmarshaler = (*Store)(nil)
+)
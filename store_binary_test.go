@@ -0,0 +1,201 @@
+package strparam
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStore_MarshalBinary(t *testing.T) {
+	s := NewStore()
+	s.AddNamed("index", "/")
+	s.AddNamed("paramed", "/{param}")
+	s.AddNamed("path", "/path/")
+	s.AddNamed("pathParams", "/path/{params}")
+	s.AddNamed("utf8", "/日本語/{p1}/СЫР")
+	require.NoError(t, s.AddPattern(&Pattern{
+		Tokens:    Tokens{StartToken, ConstToken("!"), SeparatorToken("/"), ParameterToken("param"), NamedEndToken("sep")},
+		NumParams: 1,
+	}))
+	require.NoError(t, s.AddPattern(&Pattern{
+		Tokens:    Tokens{StartToken, ConstToken("/seg/"), SegmentParameterToken("id"), NamedEndToken("segment")},
+		NumParams: 1,
+	}))
+	for i := 0; i < 50; i++ {
+		s.AddNamed(fmt.Sprintf("rand%d", i), fmt.Sprintf("%s{p1}%s{p2}golang", RandAZ(4), RandAZ(4)))
+	}
+
+	data, err := s.MarshalBinary()
+	require.NoError(t, err)
+
+	loaded := NewStore()
+	require.NoError(t, loaded.UnmarshalBinary(data))
+	assert.Equal(t, s.String(), loaded.String())
+	assert.Equal(t, s.load().maxSize, loaded.load().maxSize)
+
+	for _, in := range []string{"/", "/foo", "/path/", "/path/foo", "/日本語/123/СЫР", "!/123", "/seg/1", "/seg/1/2", "notexists", ""} {
+		want := s.Find(in)
+		got := loaded.Find(in)
+		if want == nil {
+			assert.Nil(t, got, in)
+			continue
+		}
+		require.NotNil(t, got, in)
+		assert.Equal(t, want.String(), got.String(), in)
+	}
+
+	again, err := loaded.MarshalBinary()
+	require.NoError(t, err)
+	assert.Equal(t, data, again)
+}
+
+func TestStore_UnmarshalBinary_Invalid(t *testing.T) {
+	s := NewStore()
+	s.AddNamed("a", "/{a}")
+	data, err := s.MarshalBinary()
+	require.NoError(t, err)
+
+	loaded := NewStore()
+	require.EqualError(t, loaded.UnmarshalBinary(nil), "invalid data: too short")
+	require.EqualError(t, loaded.UnmarshalBinary([]byte("XXXX\x01\x00\x00\x00\x00")), "invalid data: unknown format")
+	require.EqualError(t, loaded.UnmarshalBinary([]byte("SPST\x04\x00\x00\x00\x00")), "invalid data: not supported version 4")
+
+	broken := append([]byte{}, data...)
+	broken[len(broken)-5] ^= 0xff
+	require.EqualError(t, loaded.UnmarshalBinary(broken), "invalid data: checksum mismatch")
+
+	truncated := withChecksum(data[:len(data)-8])
+	require.Error(t, loaded.UnmarshalBinary(truncated))
+
+	// unsupported token type of root node
+	require.EqualError(t, loaded.UnmarshalBinary(withChecksum([]byte("SPST\x01\x00\x06\x00\x00\x00"))), "invalid data: not supported token type parsed_param")
+
+	// the storage is not changed after errors
+	assert.Nil(t, loaded.Find("/a"))
+}
+
+func TestStore_UnmarshalBinary_InvalidTree(t *testing.T) {
+	leaf := func(token Token) *node {
+		return &node{Token: token}
+	}
+	branch := func(token Token, childs ...*node) *node {
+		return &node{Token: token, Childs: childs}
+	}
+	root := func(childs ...*node) *node {
+		return branch(Token{}, childs...)
+	}
+	end := leaf(NamedEndToken("a"))
+
+	tests := []struct {
+		name string
+		root *node
+		want string
+	}{
+		{
+			"root is not unknown",
+			branch(StartToken, branch(ConstToken("/"), end)),
+			"invalid data: unexpected token type begin of root",
+		},
+		{
+			"unknown after root",
+			root(leaf(Token{})),
+			"invalid data: unexpected root token in branch",
+		},
+		{
+			"unknown in branch",
+			root(branch(StartToken, branch(ConstToken("/"), leaf(Token{})))),
+			"invalid data: unexpected root token in branch",
+		},
+		{
+			"start not after root",
+			root(branch(StartToken, branch(StartToken, branch(ConstToken("/"), end)))),
+			"invalid data: unexpected token type begin after begin",
+		},
+		{
+			"end with childs",
+			root(branch(StartToken, branch(ConstToken("/"), branch(NamedEndToken("a"), end)))),
+			"invalid data: unexpected childs of token type end",
+		},
+		{
+			"parameter after parameter",
+			root(branch(StartToken, branch(ParameterToken("a"), branch(ParameterToken("b"), end)))),
+			"invalid data: unexpected token type param after param",
+		},
+		{
+			"invalid length of constant",
+			root(branch(StartToken, branch(Token{Mode: CONST, Raw: "/a", Len: 5}, end))),
+			`invalid data: invalid length 5 of token Const("/a", len=5)`,
+		},
+		{
+			"invalid length of separator",
+			root(branch(StartToken, branch(Token{Mode: SEPARATOR, Raw: "/", Len: 0}, end))),
+			`invalid data: invalid length 0 of token Separator("/", len=0)`,
+		},
+		{
+			"segment flag of constant",
+			root(branch(StartToken, branch(Token{Mode: CONST, Raw: "/", Len: 1, Segment: true}, end))),
+			`invalid data: invalid flags 1 of token Const("/", len=1)`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := new(bytes.Buffer)
+			buf.WriteString(storeBinaryMagic)
+			buf.WriteByte(storeBinaryVersion)
+			writeUvarint(buf, 4)
+			writeNode(buf, tt.root)
+
+			loaded := NewStore()
+			require.EqualError(t, loaded.UnmarshalBinary(withChecksum(buf.Bytes())), tt.want)
+			assert.Nil(t, loaded.Find("/"))
+			assert.Nil(t, loaded.Find("/a"))
+		})
+	}
+}
+
+func TestStore_UnmarshalBinary_MaxSize(t *testing.T) {
+	s := NewStore()
+	s.AddNamed("a", "/{a}")
+	root := s.load().root
+
+	// forged max size of patterns
+	buf := new(bytes.Buffer)
+	buf.WriteString(storeBinaryMagic)
+	buf.WriteByte(storeBinaryVersion)
+	writeUvarint(buf, 1<<40)
+	writeNode(buf, root)
+
+	loaded := NewStore()
+	require.NoError(t, loaded.UnmarshalBinary(withChecksum(buf.Bytes())))
+	assert.Equal(t, s.load().maxSize, loaded.load().maxSize)
+	require.NotNil(t, loaded.Find("/1"))
+	match, ok := loaded.Match("/1")
+	require.True(t, ok)
+	assert.Equal(t, "a", match.Name)
+}
+
+func withChecksum(data []byte) []byte {
+	res := append([]byte{}, data...)
+	sum := make([]byte, 4)
+	binary.BigEndian.PutUint32(sum, crc32.ChecksumIEEE(res))
+	return append(res, sum...)
+}
+
+func Benchmark_Store_UnmarshalBinary_10000(b *testing.B) {
+	s := NewStore()
+	for i := 0; i < 10000; i++ {
+		s.Add(fmt.Sprintf("%s{p1}%s{p2}golang", RandAZ(4), RandAZ(4)))
+	}
+	data, _ := s.MarshalBinary()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		NewStore().UnmarshalBinary(data)
+	}
+}
@@ -0,0 +1,188 @@
+package strparam
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// run with -race
+func Test_Store_Concurrent(t *testing.T) {
+	const (
+		numPatterns = 50
+		numReaders  = 16
+		numWriters  = 4
+		numOps      = 500
+	)
+
+	r := NewStore()
+	// stable patterns are never removed
+	for i := 0; i < numPatterns; i++ {
+		_, err := r.AddNamed(fmt.Sprintf("stable%d", i), fmt.Sprintf("/stable/%d/{id}", i))
+		require.NoError(t, err)
+	}
+
+	var wg sync.WaitGroup
+	var failed int32
+	fail := func(format string, args ...interface{}) {
+		if atomic.AddInt32(&failed, 1) == 1 {
+			t.Errorf(format, args...)
+		}
+	}
+
+	for w := 0; w < numWriters; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			rnd := rand.New(rand.NewSource(int64(w)))
+			for i := 0; i < numOps; i++ {
+				name := fmt.Sprintf("dynamic%d", rnd.Intn(numPatterns))
+				switch rnd.Intn(3) {
+				case 0:
+					// returns error if exists
+					if _, err := r.AddNamed(name, "/dynamic/"+name+"/{id}"); err != nil && !strings.HasSuffix(err.Error(), "already exists") {
+						fail("add: %v", err)
+					}
+				case 1:
+					r.RemoveNamed(name)
+				case 2:
+					// replace returns error if not exists
+					r.Replace(name, "/dynamic/"+name+"/{id}") // nolint: errcheck
+				}
+			}
+		}(w)
+	}
+
+	for g := 0; g < numReaders; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			rnd := rand.New(rand.NewSource(int64(100 + g)))
+			for i := 0; i < numOps; i++ {
+				n := rnd.Intn(numPatterns)
+				id := fmt.Sprintf("id%d-%d", g, i)
+
+				in := fmt.Sprintf("/stable/%d/%s", n, id)
+				found := r.Find(in)
+				if found == nil {
+					fail("stable pattern not found for %q", in)
+					continue
+				}
+				ok, params := found.Lookup(in)
+				if !ok || found.Name() != fmt.Sprintf("stable%d", n) || len(params) != 1 || params[0].Value != id {
+					fail("unexpected match %v %v of %q by %s", ok, params, in, found)
+				}
+
+				in = fmt.Sprintf("/dynamic/dynamic%d/%s", n, id)
+				if found := r.Find(in); found != nil {
+					ok, params := found.Lookup(in)
+					if !ok || found.Name() != fmt.Sprintf("dynamic%d", n) || params[0].Value != id {
+						fail("unexpected match %v %v of %q by %s", ok, params, in, found)
+					}
+				}
+
+				occs := r.Scan("text " + in + " text")
+				if len(occs) > 1 {
+					fail("unexpected occurrences %v", occs)
+				}
+				if _, err := r.MarshalBinary(); err != nil {
+					fail("marshal: %v", err)
+				}
+			}
+		}(g)
+	}
+
+	wg.Wait()
+
+	for i := 0; i < numPatterns; i++ {
+		assert.Equal(t, fmt.Sprintf("stable%d", i), r.Find(fmt.Sprintf("/stable/%d/1", i)).Name())
+	}
+}
+
+func Test_Store_CopyOnWrite(t *testing.T) {
+	r := NewStore()
+	_, err := r.AddNamed("a", "/a/{id}")
+	require.NoError(t, err)
+
+	before := r.load()
+	schema := r.String()
+
+	_, err = r.AddNamed("b", "/a/{id}/b")
+	require.NoError(t, err)
+	assert.True(t, r.RemoveNamed("a"))
+
+	// the previous version of tree is not changed
+	prev := &Store{}
+	prev.state.Store(before)
+	assert.Equal(t, schema, prev.String())
+}
+
+// run with -race
+func Test_Store_Replace_Atomic(t *testing.T) {
+	const (
+		numReaders = 8
+		numOps     = 1000
+	)
+
+	r := NewStore()
+	_, err := r.AddNamed("replaced", "/replaced/{id}")
+	require.NoError(t, err)
+
+	var wg sync.WaitGroup
+	var failed int32
+	fail := func(format string, args ...interface{}) {
+		if atomic.AddInt32(&failed, 1) == 1 {
+			t.Errorf(format, args...)
+		}
+	}
+
+	done := make(chan struct{})
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		defer close(done)
+		// both patterns match the same input
+		exps := []string{"/replaced/{name}", "/replaced/{id}"}
+		for i := 0; i < numOps; i++ {
+			if _, err := r.Replace("replaced", exps[i%2]); err != nil {
+				fail("replace: %v", err)
+			}
+		}
+	}()
+
+	for g := 0; g < numReaders; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-done:
+					return
+				default:
+				}
+				found := r.Find("/replaced/1")
+				if found == nil {
+					fail("pattern is not found during replace")
+					return
+				}
+				if found.Name() != "replaced" {
+					fail("unexpected pattern %s", found)
+					return
+				}
+				if r.Len() != 1 {
+					fail("unexpected number of patterns %d", r.Len())
+					return
+				}
+			}
+		}()
+	}
+
+	wg.Wait()
+	assert.Equal(t, 1, r.Len())
+}
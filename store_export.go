@@ -0,0 +1,147 @@
+package strparam
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// jsonNode is representation of the node of tree in JSON.
+type jsonNode struct {
+	Mode string `json:"mode"`
+	Raw  string `json:"raw,omitempty"`
+	// Name name of pattern (for END node) or name of parameter
+	Name string `json:"name,omitempty"`
+	// Segment value of parameter is limited by segment of path
+	Segment bool `json:"segment,omitempty"`
+	// Order index of node among the siblings (the order of walk)
+	Order  int `json:"order"`
+	Weight int `json:"weight,omitempty"`
+	// Matched node of the path taken by Find for the input
+	Matched bool        `json:"matched,omitempty"`
+	Childs  []*jsonNode `json:"childs,omitempty"`
+}
+
+// MarshalJSON implements json.Marshaler interface.
+//
+// Returns the sorted tree of patterns: each node has mode, raw value of token, name (of pattern or parameter),
+// order among the siblings and the childs.
+func (r *Store) MarshalJSON() ([]byte, error) {
+	return json.Marshal(newJSONNode(r.load().root, 0, nil))
+}
+
+// MarshalJSONWithPath returns the tree of patterns same as MarshalJSON,
+// the nodes of the path taken by Find for the input are marked as matched
+// (the path may be incomplete if no pattern is found).
+func (r *Store) MarshalJSONWithPath(in string) ([]byte, error) {
+	root := r.load().root
+	return json.Marshal(newJSONNode(root, 0, matchedPath(root, in)))
+}
+
+func newJSONNode(n *node, order int, matched map[*node]bool) *jsonNode {
+	res := &jsonNode{
+		Mode:    n.Token.Mode.String(),
+		Raw:     n.Token.Raw,
+		Segment: n.Token.Segment,
+		Order:   order,
+		Weight:  n.weight,
+		Matched: matched[n],
+	}
+	switch n.Token.Mode {
+	case UNKNOWN_TokenMode:
+		res.Mode = "root"
+	case END:
+		res.Raw, res.Name = "", n.Token.Raw
+	case PARAMETER:
+		res.Name = n.Token.ParamName()
+	}
+	for i, child := range n.Childs {
+		res.Childs = append(res.Childs, newJSONNode(child, i, matched))
+	}
+	return res
+}
+
+// WriteDOT writes the sorted tree of patterns in Graphviz DOT format (eg for `dot -Tsvg`).
+//
+// Edges are labeled by order of the child among the siblings, END nodes are labeled by name of pattern.
+func (r *Store) WriteDOT(w io.Writer) error {
+	return writeDOT(w, r.load().root, nil)
+}
+
+// WriteDOTWithPath writes the tree of patterns same as WriteDOT,
+// the nodes of the path taken by Find for the input are highlighted
+// (the path may be incomplete if no pattern is found).
+func (r *Store) WriteDOTWithPath(w io.Writer, in string) error {
+	root := r.load().root
+	return writeDOT(w, root, matchedPath(root, in))
+}
+
+func writeDOT(w io.Writer, root *node, matched map[*node]bool) error {
+	buf := bufio.NewWriter(w)
+	fmt.Fprintln(buf, "digraph strparam {")
+	fmt.Fprintln(buf, "\tnode [shape=box, fontname=\"monospace\"];")
+
+	id := 0
+	var walk func(n *node)
+	walk = func(n *node) {
+		nodeID := id
+		id++
+
+		label, attrs := n.Token.String(), ""
+		switch n.Token.Mode {
+		case UNKNOWN_TokenMode:
+			label = "root"
+		case END:
+			attrs += ", shape=oval"
+		}
+		if n.weight != 0 {
+			label += fmt.Sprintf("\nweight=%d", n.weight)
+		}
+		if matched[n] {
+			attrs += ", style=filled, fillcolor=lightblue"
+		}
+		fmt.Fprintf(buf, "\tn%d [label=%q%s];\n", nodeID, label, attrs)
+
+		for i, child := range n.Childs {
+			// the child gets the next id
+			attrs := ""
+			if matched[n] && matched[child] {
+				attrs = ", color=blue, penwidth=2"
+			}
+			fmt.Fprintf(buf, "\tn%d -> n%d [label=\"%d\"%s];\n", nodeID, id, i, attrs)
+			walk(child)
+		}
+	}
+	walk(root)
+
+	fmt.Fprintln(buf, "}")
+	return buf.Flush()
+}
+
+// matchedPath returns the nodes of the path taken by Find for the input (including root).
+func matchedPath(root *node, in string) map[*node]bool {
+	var tokens []Token
+	numParams := 0
+	lookup(in, root, &tokens, &numParams, nil)
+
+	res := map[*node]bool{root: true}
+	cur := root
+	for _, t := range tokens {
+		var next *node
+		for _, child := range cur.Childs {
+			if t.Mode == PARAMETER_PARSED && t.Param == &child.Token || child.Token.Equal(t) {
+				next = child
+				break
+			}
+		}
+		if next == nil {
+			break
+		}
+		res[next] = true
+		cur = next
+	}
+	return res
+}
+
+var _ json.Marshaler = (*Store)(nil)
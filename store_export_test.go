@@ -0,0 +1,100 @@
+package strparam
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStore_WriteDOT(t *testing.T) {
+	s := NewStore()
+	s.AddNamed("index", "/")
+	s.AddNamed("users", "/users/{id}")
+
+	buf := new(bytes.Buffer)
+	require.NoError(t, s.WriteDOT(buf))
+	assert.Equal(t, `digraph strparam {
+	node [shape=box, fontname="monospace"];
+	n0 [label="root"];
+	n0 -> n1 [label="0"];
+	n1 [label="START"];
+	n1 -> n2 [label="0"];
+	n2 [label="Const(\"/users/\", len=7)"];
+	n2 -> n3 [label="0"];
+	n3 [label="Param(\"{id}\")"];
+	n3 -> n4 [label="0"];
+	n4 [label="END(\"users\")", shape=oval];
+	n1 -> n5 [label="1"];
+	n5 [label="Const(\"/\", len=1)"];
+	n5 -> n6 [label="0"];
+	n6 [label="END(\"index\")", shape=oval];
+}
+`, buf.String())
+
+	buf.Reset()
+	require.NoError(t, s.WriteDOTWithPath(buf, "/users/1"))
+	assert.Contains(t, buf.String(), `n3 [label="Param(\"{id}\")", style=filled, fillcolor=lightblue];`)
+	assert.Contains(t, buf.String(), `n3 -> n4 [label="0", color=blue, penwidth=2];`)
+	assert.Contains(t, buf.String(), `n5 [label="Const(\"/\", len=1)"];`)
+
+	buf.Reset()
+	require.NoError(t, (&Store{}).WriteDOT(buf))
+	assert.Equal(t, "digraph strparam {\n\tnode [shape=box, fontname=\"monospace\"];\n\tn0 [label=\"root\"];\n}\n", buf.String())
+}
+
+func TestStore_MarshalJSON(t *testing.T) {
+	s := NewStore()
+	s.AddNamed("index", "/")
+	s.AddNamed("users", "/users/{id}")
+	s.AddWithPriority("/files/{path}", 1)
+
+	data, err := json.Marshal(s)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"mode": "root", "order": 0, "weight": 1, "childs": [
+		{"mode": "begin", "order": 0, "weight": 1, "childs": [
+			{"mode": "const", "raw": "/files/", "order": 0, "weight": 1, "childs": [
+				{"mode": "param", "raw": "{path}", "name": "path", "order": 0, "weight": 1, "childs": [
+					{"mode": "end", "order": 0, "weight": 1}
+				]}
+			]},
+			{"mode": "const", "raw": "/users/", "order": 1, "childs": [
+				{"mode": "param", "raw": "{id}", "name": "id", "order": 0, "childs": [
+					{"mode": "end", "name": "users", "order": 0}
+				]}
+			]},
+			{"mode": "const", "raw": "/", "order": 2, "childs": [
+				{"mode": "end", "name": "index", "order": 0}
+			]}
+		]}
+	]}`, string(data))
+
+	var matched []string
+	var collect func(n *jsonNode)
+	collect = func(n *jsonNode) {
+		if n.Matched {
+			matched = append(matched, n.Mode+":"+n.Raw+n.Name)
+		}
+		for _, child := range n.Childs {
+			collect(child)
+		}
+	}
+
+	data, err = s.MarshalJSONWithPath("/users/1")
+	require.NoError(t, err)
+	root := &jsonNode{}
+	require.NoError(t, json.Unmarshal(data, root))
+	collect(root)
+	assert.Equal(t, []string{"root:", "begin:", "const:/users/", "param:{id}id", "end:users"}, matched)
+
+	// incomplete path (no constant is matched)
+	matched = nil
+	data, err = s.MarshalJSONWithPath("/files")
+	require.NoError(t, err)
+	root = &jsonNode{}
+	require.NoError(t, json.Unmarshal(data, root))
+	collect(root)
+	assert.Equal(t, []string{"root:", "begin:"}, matched)
+}
@@ -2,8 +2,11 @@ package strparam
 
 import (
 	"fmt"
+	"math/rand"
 	"reflect"
+	"sort"
 	"testing"
+	"unsafe"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -351,3 +354,566 @@ func Test_nextHas(t *testing.T) {
 	assert.True(t, n.nextHas(CONST))
 	assert.False(t, n.nextHas(END))
 }
+
+func Test_Store_Remove(t *testing.T) {
+	r := NewStore()
+	_, err := r.Add("/users/{id}")
+	require.NoError(t, err)
+	_, err = r.AddNamed("files", "/users/{id}/files/{name}")
+	require.NoError(t, err)
+	_, err = r.AddNamed("home", "/")
+	require.NoError(t, err)
+	empty := NewStore()
+	require.NoError(t, err)
+
+	assert.Equal(t, 6, r.load().maxSize)
+
+	// named pattern is not removed by Remove
+	removed, err := r.Remove("/users/{id}/files/{name}")
+	require.NoError(t, err)
+	assert.False(t, removed)
+
+	assert.True(t, r.RemoveNamed("files"))
+	assert.False(t, r.RemoveNamed("files"))
+	assert.Equal(t, "/users/{id}", r.Find("/users/1/files/a.txt").Source())
+	assert.Equal(t, 4, r.load().maxSize)
+	assert.Equal(t, "/users/{id}", r.Find("/users/1").Source())
+
+	removed, err = r.Remove("/users/{id}")
+	require.NoError(t, err)
+	assert.True(t, removed)
+	assert.Nil(t, r.Find("/users/1"))
+
+	_, err = r.Remove("{")
+	require.EqualError(t, err, "failed parse: parameter was not closed, pos 0")
+
+	// the empty branches are pruned
+	assert.True(t, r.RemoveNamed("home"))
+	assert.Equal(t, empty.String(), r.String())
+	assert.Equal(t, 0, r.load().maxSize)
+
+	_, err = r.AddNamed("home", "/")
+	require.NoError(t, err)
+	assert.Equal(t, "home", r.Find("/").Name())
+}
+
+func Test_Store_Replace(t *testing.T) {
+	r := NewStore()
+	_, err := r.AddNamed("user", "/users/{id}")
+	require.NoError(t, err)
+	_, err = r.AddNamed("usersList", "/users")
+	require.NoError(t, err)
+
+	p, err := r.Replace("user", "/u/{id}")
+	require.NoError(t, err)
+	assert.Equal(t, "/u/{id}", p.Source())
+	assert.Nil(t, r.Find("/users/1"))
+	assert.Equal(t, "user", r.Find("/u/1").Name())
+	assert.Equal(t, "usersList", r.Find("/users").Name())
+
+	_, err = r.Replace("user", "{")
+	require.EqualError(t, err, "failed parse: parameter was not closed, pos 0")
+	assert.Equal(t, "user", r.Find("/u/1").Name())
+
+	_, err = r.Replace("unknown", "/a")
+	require.EqualError(t, err, `pattern "unknown" not found`)
+	assert.Nil(t, r.Find("/a"))
+}
+
+func Test_Store_Remove_Sorting(t *testing.T) {
+	// sorting of siblings depends on number of childs
+	r := NewStore()
+	for _, exp := range []string{"a{p}b", "a{p}c", "a{q}x", "a{q}y", "a{q}z"} {
+		_, err := r.AddNamed(exp, exp)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, "a{q}x", r.Find("a1x").Name())
+
+	assert.True(t, r.RemoveNamed("a{q}y"))
+	assert.True(t, r.RemoveNamed("a{q}z"))
+
+	want := NewStore()
+	for _, exp := range []string{"a{p}b", "a{p}c", "a{q}x"} {
+		_, err := want.AddNamed(exp, exp)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, want.String(), r.String())
+	assert.Equal(t, "a{p}b", r.Find("a1b").Name())
+	assert.Equal(t, "a{q}x", r.Find("a1x").Name())
+}
+
+func Test_Store_Match_BasicTests(t *testing.T) {
+	// result should be the same as in the case Find and Lookup for same pattern
+	for _, tt := range patternBasicCases {
+		t.Run(fmt.Sprintf("%s: %q->%q", tt.name, tt.pattern, tt.in), func(t *testing.T) {
+			if tt.wantErr {
+				t.Skip("because want error")
+			}
+
+			s := NewStore()
+			added, err := s.Add(tt.pattern)
+			require.NoError(t, err)
+
+			match, found := s.Match(tt.in)
+			require.Equal(t, tt.found, found)
+			if !found {
+				assert.Empty(t, match)
+				return
+			}
+			assert.Equal(t, added, match.Pattern)
+			assert.Equal(t, "", match.Name)
+			if len(tt.want) == 0 {
+				assert.Empty(t, match.Params)
+			} else {
+				assert.EqualValues(t, tt.want, match.Params)
+			}
+		})
+	}
+}
+
+func Test_Store_Match(t *testing.T) {
+	s := NewStore()
+	patterns := map[string]*Pattern{}
+	for _, rawPattern := range [][]string{
+		{"a", "/{foobar}"},
+		{"b", "/"},
+		{"c", "/foo/{bar}"},
+		{"d", "/a"},
+		{"d1", "/a/1"},
+		{"d1*", "/a/1/{param}"},
+		{"e", "/e/{p1}/{p2}"},
+	} {
+		p, err := s.AddNamed(rawPattern[0], rawPattern[1])
+		require.NoError(t, err)
+		patterns[rawPattern[0]] = p
+	}
+
+	for _, in := range []string{"/", "/a", "/a/1", "/a/1/2", "/foo/bar", "/e/1/2", "/baz/123", "", "a"} {
+		match, found := s.Match(in)
+
+		want := s.Find(in)
+		if want == nil {
+			assert.False(t, found, in)
+			continue
+		}
+		require.True(t, found, in)
+		wantFound, wantParams := want.Lookup(in)
+		require.True(t, wantFound, in)
+
+		assert.Equal(t, want.Name(), match.Name, in)
+		assert.Same(t, patterns[match.Name], match.Pattern, in)
+		if len(wantParams) == 0 {
+			assert.Empty(t, match.Params, in)
+		} else {
+			assert.EqualValues(t, wantParams, match.Params, in)
+		}
+	}
+
+	match, found := s.Match("/e/1/2")
+	assert.True(t, found)
+	assert.EqualValues(t, Params{{"p1", "1"}, {"p2", "2"}}, match.Params)
+
+	// patterns are restored after decoding from binary format
+	data, err := s.MarshalBinary()
+	require.NoError(t, err)
+	loaded := NewStore()
+	require.NoError(t, loaded.UnmarshalBinary(data))
+	match, found = loaded.Match("/e/1/2")
+	assert.True(t, found)
+	assert.Equal(t, "e", match.Name)
+	assert.Equal(t, patterns["e"].String(), match.Pattern.String())
+	assert.Equal(t, 2, match.Pattern.NumParams)
+}
+
+func Test_Store_Match_Allocs(t *testing.T) {
+	s := NewStore()
+	s.Add("foo2{p1}foo2{p2}golang")
+	s.Add("foo1{p3}foo1{p4}golang")
+	in := "foo1XXXfoo1YYYgolang"
+
+	if raceEnabled {
+		t.Skip("allocations are not stable with the race detector")
+	}
+
+	// Find of the same input allocated 2 times (56 B) before Match,
+	// Match allocates the list of params only (2 params of 32 B)
+	res := testing.Benchmark(func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			s.Match(in)
+		}
+	})
+	assert.LessOrEqual(t, res.AllocsPerOp(), int64(1))
+	assert.LessOrEqual(t, res.AllocedBytesPerOp(), int64(2*unsafe.Sizeof(Param{})))
+
+	findAndLookup := testing.AllocsPerRun(100, func() {
+		s.Find(in).Lookup(in)
+	})
+	match := testing.AllocsPerRun(100, func() {
+		s.Match(in)
+	})
+	assert.Less(t, match, findAndLookup)
+
+	// without parameters
+	s.Add("/static")
+	match = testing.AllocsPerRun(100, func() {
+		s.Match("/static")
+	})
+	assert.Zero(t, match)
+}
+
+func Benchmark_Store_Match_2_2(b *testing.B) {
+	r := NewStore()
+	r.Add("foo2{p1}foo2{p2}golang")
+	r.Add("foo1{p3}foo1{p4}golang")
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	in := "foo1XXXfoo1YYYgolang"
+	for i := 0; i < b.N; i++ {
+		r.Match(in)
+	}
+}
+
+func Benchmark_Store_FindAndLookup_2_2(b *testing.B) {
+	r := NewStore()
+	r.Add("foo2{p1}foo2{p2}golang")
+	r.Add("foo1{p3}foo1{p4}golang")
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	in := "foo1XXXfoo1YYYgolang"
+	for i := 0; i < b.N; i++ {
+		r.Find(in).Lookup(in)
+	}
+}
+
+func Test_Store_AddWithValue(t *testing.T) {
+	s := NewStore()
+	users, err := s.AddWithValue("/users/{id}", 1)
+	require.NoError(t, err)
+	_, err = s.AddWithValue("/users/{id}/files", "files")
+	require.NoError(t, err)
+	s.Add("/")
+	_, err = s.AddWithValue("/{", nil)
+	require.Error(t, err)
+
+	match, found := s.Match("/users/123")
+	require.True(t, found)
+	assert.Equal(t, 1, match.Value)
+	assert.Same(t, users, match.Pattern)
+	assert.EqualValues(t, Params{{"id", "123"}}, match.Params)
+
+	match, found = s.Match("/users/123/files")
+	require.True(t, found)
+	assert.Equal(t, "files", match.Value)
+
+	match, found = s.Match("/")
+	require.True(t, found)
+	assert.Nil(t, match.Value)
+
+	// the value is replaced, the previous version of tree is not changed
+	before := s.load()
+	_, err = s.AddWithValue("/users/{id}", 2)
+	require.NoError(t, err)
+	match, _ = s.Match("/users/123")
+	assert.Equal(t, 2, match.Value)
+	var (
+		tokens    []Token
+		numParams int
+		end       *node
+	)
+	lookupNextToken("/users/123", 0, before.root, &tokens, &numParams, &end, nil)
+	require.NotNil(t, end)
+	assert.Equal(t, 1, end.value)
+
+	named, err := ParseWithName("named", "/named/{id}")
+	require.NoError(t, err)
+	require.NoError(t, s.AddPatternWithValue(named, 3))
+	match, _ = s.Match("/named/1")
+	assert.Equal(t, "named", match.Name)
+	assert.Equal(t, 3, match.Value)
+}
+
+func Test_Store_AddPattern_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		p    *Pattern
+		want string
+	}{
+		{"nil", nil, "invalid pattern: empty pattern"},
+		{"without start", &Pattern{Tokens: Tokens{ConstToken("/"), EndToken}}, "invalid pattern: unexpected token type const at begin"},
+		{"without end", &Pattern{Tokens: Tokens{StartToken, ConstToken("/")}}, "invalid pattern: unexpected token type const at end"},
+		{
+			"parameter after parameter",
+			&Pattern{Tokens: Tokens{StartToken, ParameterToken("a"), ParameterToken("b"), EndToken}, NumParams: 2},
+			"invalid pattern: should be a pattern between the parameters, token 2",
+		},
+		{
+			"parsed parameter",
+			&Pattern{Tokens: Tokens{StartToken, ParsedParameterToken("a", "1"), EndToken}, NumParams: 1},
+			"invalid pattern: unexpected token type parsed_param, token 1",
+		},
+		{
+			"invalid length of constant",
+			&Pattern{Tokens: Tokens{StartToken, Token{Mode: CONST, Raw: "/a", Len: 1}, EndToken}},
+			`invalid pattern: invalid length 1 of token Const("/a", len=1)`,
+		},
+		{
+			"number of parameters",
+			&Pattern{Tokens: Tokens{StartToken, ConstToken("/"), ParameterToken("a"), EndToken}},
+			"invalid pattern: number of parameters is 1, expected 0",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewStore()
+			require.EqualError(t, s.AddPatternWithValue(tt.p, 1), tt.want)
+			require.EqualError(t, s.AddPattern(tt.p), tt.want)
+			assert.Equal(t, 0, s.Len())
+			assert.Equal(t, NewStore().String(), s.String())
+		})
+	}
+}
+
+func Test_Store_AddWithPriority(t *testing.T) {
+	tests := []struct {
+		name     string
+		patterns [][]interface{}
+		in       string
+		want     string
+	}{
+		{
+			"constant wins by default",
+			[][]interface{}{{"/foo", 0}, {"/{x}", 0}},
+			"/foo", "/foo",
+		},
+		{
+			"parameter with higher priority",
+			[][]interface{}{{"/foo", 0}, {"/{x}", 1}},
+			"/foo", "/{x}",
+		},
+		{
+			"parameter with higher priority (reverse order)",
+			[][]interface{}{{"/{x}", 1}, {"/foo", 0}},
+			"/foo", "/{x}",
+		},
+		{
+			"negative priority",
+			[][]interface{}{{"/{x}", 0}, {"/foo", -1}},
+			"/foo", "/{x}",
+		},
+		{
+			"longer constant with lower priority",
+			[][]interface{}{{"/foo/{x}", 0}, {"/foo/bar/{x}", 0}, {"/foo/{y}/baz", 5}},
+			"/foo/bar/baz", "/foo/{y}/baz",
+		},
+		{
+			// the pattern with higher priority does not match the input
+			"priority does not hide other patterns",
+			[][]interface{}{{"/foo/{x}", 0}, {"/foo/bar/{x}", 0}, {"/foo/{y}/baz", 5}},
+			"/foo/bar/qux", "/foo/bar/{x}",
+		},
+		{
+			"prioritized branch fails partway",
+			[][]interface{}{{"/abc", 0}, {"/a{x}b", 10}},
+			"/abc", "/abc",
+		},
+		{
+			"prioritized branch matches",
+			[][]interface{}{{"/abc", 0}, {"/a{x}b", 10}},
+			"/acb", "/a{x}b",
+		},
+		{
+			"equal priorities",
+			[][]interface{}{{"/foo/{x}", 5}, {"/foo/bar/{x}", 5}, {"/foo/{y}/baz", 5}},
+			"/foo/bar/qux", "/foo/bar/{x}",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewStore()
+			for _, p := range tt.patterns {
+				added, err := s.AddWithPriority(p[0].(string), p[1].(int))
+				require.NoError(t, err)
+				assert.Equal(t, p[1], added.Priority)
+			}
+
+			match, found := s.Match(tt.in)
+			require.True(t, found)
+			assert.Equal(t, tt.want, match.Pattern.Source())
+		})
+	}
+}
+
+func Test_Store_AddWithPriority_RemoveAndBinary(t *testing.T) {
+	s := NewStore()
+	s.Add("/foo")
+	s.AddWithPriority("/{x}", 10)
+	s.AddWithPriority("/{x}/bar", 20)
+
+	match, _ := s.Match("/foo")
+	assert.Equal(t, "/{x}", match.Pattern.Source())
+
+	// priorities are saved in binary format
+	data, err := s.MarshalBinary()
+	require.NoError(t, err)
+	loaded := NewStore()
+	require.NoError(t, loaded.UnmarshalBinary(data))
+	match, _ = loaded.Match("/foo")
+	assert.Equal(t, "/{x}", match.Pattern.Source())
+	assert.Equal(t, 10, match.Pattern.Priority)
+
+	// the weight of branch is updated after removal
+	require.True(t, s.RemovePattern(&Pattern{Tokens: Tokens{StartToken, ConstToken("/"), ParameterToken("x"), EndToken}}))
+	root := s.load().root
+	assert.Equal(t, 20, root.weight)
+	require.True(t, s.RemovePattern(&Pattern{Tokens: Tokens{StartToken, ConstToken("/"), ParameterToken("x"), ConstToken("/bar"), EndToken}}))
+	assert.Equal(t, 0, s.load().root.weight)
+	match, _ = s.Match("/foo")
+	assert.Equal(t, "/foo", match.Pattern.Source())
+}
+
+// checks that the pattern with higher priority wins among all matched patterns (see FindAll)
+func Test_Store_AddWithPriority_AmongMatched(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	segments := []string{"a", "ab", "b", "{x}", "{y}"}
+
+	s := NewStore()
+	for i := 0; i < 100; i++ {
+		exp := ""
+		for j, num := 0, 1+rnd.Intn(4); j < num; j++ {
+			exp += "/" + segments[rnd.Intn(len(segments))]
+		}
+		priority := 0
+		if rnd.Intn(5) == 0 {
+			priority = rnd.Intn(7) - 2
+		}
+		s.AddWithPriority(exp, priority)
+	}
+
+	for i := 0; i < 500; i++ {
+		in := ""
+		for j, num := 0, rnd.Intn(5); j < num; j++ {
+			in += "/" + []string{"a", "ab", "b", "c", ""}[rnd.Intn(5)]
+		}
+		all := s.FindAll(in)
+		match, found := s.Match(in)
+		best := 0
+		if found {
+			best = match.Pattern.Priority
+			var patterns []*Pattern
+			for _, m := range all {
+				patterns = append(patterns, m.Pattern)
+			}
+			require.Contains(t, patterns, match.Pattern, in)
+			ok, params := match.Pattern.Lookup(in)
+			require.True(t, ok, in)
+			require.Equal(t, fmt.Sprint(params), fmt.Sprint(match.Params), in)
+			require.Equal(t, ListTokensSchemaString(match.Pattern.Tokens), ListTokensSchemaString(s.Find(in).Tokens), in)
+			require.Equal(t, s.Find(in).String(), s.Explain(in).Found.String(), in)
+		}
+		for _, m := range all {
+			require.LessOrEqual(t, m.Pattern.Priority, best, "%q: %s wins over %s", in, m.Pattern.Source(), match.Pattern)
+		}
+	}
+}
+
+func Test_Store_OrderIndependent(t *testing.T) {
+	// the result of Find does not depend on the order of appends
+	rnd := rand.New(rand.NewSource(1))
+	segments := []string{"a", "ab", "b", "{x}", "{y}", "{z}"}
+
+	type added struct {
+		name, exp string
+		priority  int
+	}
+	var patterns []added
+	seen := map[string]bool{}
+	for len(patterns) < 200 {
+		exp := ""
+		for i, num := 0, 1+rnd.Intn(4); i < num; i++ {
+			exp += "/" + segments[rnd.Intn(len(segments))]
+		}
+		if seen[exp] {
+			continue
+		}
+		seen[exp] = true
+		priority := 0
+		if rnd.Intn(10) == 0 {
+			priority = rnd.Intn(3)
+		}
+		patterns = append(patterns, added{fmt.Sprintf("p%d", len(patterns)), exp, priority})
+	}
+
+	var inputs []string
+	for i := 0; i < 500; i++ {
+		in := ""
+		for j, num := 0, rnd.Intn(5); j < num; j++ {
+			in += "/" + []string{"a", "ab", "b", "c", "", "ba"}[rnd.Intn(6)]
+		}
+		inputs = append(inputs, in)
+	}
+
+	build := func(order []int) *Store {
+		s := NewStore()
+		for _, i := range order {
+			p, err := ParseWithName(patterns[i].name, patterns[i].exp)
+			require.NoError(t, err)
+			p.Priority = patterns[i].priority
+			require.NoError(t, s.AddPattern(p))
+		}
+		return s
+	}
+	results := func(s *Store) []string {
+		res := make([]string, 0, len(inputs))
+		for _, in := range inputs {
+			if found := s.Find(in); found != nil {
+				res = append(res, found.String())
+			} else {
+				res = append(res, "")
+			}
+		}
+		return res
+	}
+
+	order := rnd.Perm(len(patterns))
+	want := build(order)
+	wantResults := results(want)
+	for i := 0; i < 20; i++ {
+		rnd.Shuffle(len(order), func(i, j int) { order[i], order[j] = order[j], order[i] })
+		got := build(order)
+		require.Equal(t, want.String(), got.String())
+		require.Equal(t, wantResults, results(got))
+	}
+
+	// removal keeps the same order
+	got := build(rnd.Perm(len(patterns)))
+	for _, i := range rnd.Perm(len(patterns))[:50] {
+		p, err := ParseWithName(patterns[i].name, patterns[i].exp)
+		require.NoError(t, err)
+		require.True(t, got.RemovePattern(p))
+		want.RemovePattern(p)
+	}
+	rebuilt := NewStore()
+	for _, p := range want.Patterns() {
+		require.NoError(t, rebuilt.AddPattern(p))
+	}
+	assert.Equal(t, rebuilt.String(), got.String())
+	assert.Equal(t, results(rebuilt), results(got))
+}
+
+func Test_node_Less_Specificity(t *testing.T) {
+	catchAll := &node{Token: ParameterToken("all"), Childs: []*node{{Token: EndToken}}}
+	param := &node{Token: ParameterToken("param"), Childs: []*node{{Token: ConstToken("/")}}}
+	end := &node{Token: EndToken}
+	short := &node{Token: ConstToken("a")}
+	long := &node{Token: ConstToken("abc")}
+	sep := &node{Token: SeparatorToken("/")}
+	weighted := &node{Token: ParameterToken("w"), Childs: []*node{{Token: EndToken}}, weight: 1}
+
+	n := &node{Childs: []*node{catchAll, end, short, param, sep, long, weighted}}
+	sort.Sort(n)
+	// the weight (priority) does not change the order
+	assert.Equal(t, []*node{long, short, sep, end, param, catchAll, weighted}, n.Childs)
+}
@@ -0,0 +1,311 @@
+// Package strparamtest runs test-spec files of patterns.
+//
+// The spec file (YAML) lists named patterns with example inputs and expected params and non-matches.
+//
+//	patterns:
+//	  - name: login
+//	    pattern: user {name} logged in from {ip}
+//	    match:
+//	      - input: user bob logged in from 10.0.0.1
+//	        params: {name: bob, ip: 10.0.0.1}
+//	    nomatch:
+//	      - user bob logged out
+//	  - name: logout
+//	    pattern: user {name} logged out
+//	    match:
+//	      - input: user bob logged out
+//	# inputs that should not be matched by any pattern
+//	unmatched:
+//	  - something else
+//
+// All patterns are added into strparam.Store (in order of the file) and every example is checked by
+// strparam.Store.Find and strparam.Pattern.Lookup:
+// - example of match should be matched by its pattern (the pattern wins) with expected params
+// (params are not checked if omitted, use {} for no params)
+// - example of nomatch should not be matched by its pattern (may be matched by other pattern)
+// - example of unmatched should not be matched by any pattern
+package strparamtest
+
+import (
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
+
+	"github.com/gebv/strparam"
+)
+
+// Spec test-spec of patterns.
+type Spec struct {
+	Patterns  []SpecPattern `yaml:"patterns"`
+	Unmatched []string      `yaml:"unmatched"`
+}
+
+// SpecPattern named pattern with examples.
+type SpecPattern struct {
+	Name    string        `yaml:"name"`
+	Pattern string        `yaml:"pattern"`
+	Match   []SpecExample `yaml:"match"`
+	NoMatch []string      `yaml:"nomatch"`
+}
+
+// SpecExample example of input matched by the pattern.
+type SpecExample struct {
+	Input  string            `yaml:"input"`
+	Params map[string]string `yaml:"params"`
+}
+
+// LoadSpec returns spec from the file.
+func LoadSpec(path string) (*Spec, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	spec, err := ParseSpec(data)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed load spec %q", path)
+	}
+	return spec, nil
+}
+
+// ParseSpec returns spec from YAML.
+func ParseSpec(data []byte) (*Spec, error) {
+	spec := &Spec{}
+	if err := yaml.UnmarshalStrict(data, spec); err != nil {
+		return nil, err
+	}
+	if len(spec.Patterns) == 0 {
+		return nil, errors.New("no patterns")
+	}
+
+	names := map[string]bool{}
+	for i, p := range spec.Patterns {
+		if p.Name == "" {
+			return nil, errors.Errorf("pattern #%d: empty name", i+1)
+		}
+		if names[p.Name] {
+			return nil, errors.Errorf("pattern #%d: duplicate name %q", i+1, p.Name)
+		}
+		names[p.Name] = true
+	}
+	return spec, nil
+}
+
+// Store returns the storage with all patterns of spec.
+func (s *Spec) Store() (*strparam.Store, error) {
+	store := strparam.NewStore()
+	for _, p := range s.Patterns {
+		if _, err := store.AddNamed(p.Name, p.Pattern); err != nil {
+			return nil, errors.Wrapf(err, "pattern %q", p.Name)
+		}
+	}
+	return store, nil
+}
+
+// Run checks all examples of spec.
+func (s *Spec) Run() (*Report, error) {
+	store, err := s.Store()
+	if err != nil {
+		return nil, err
+	}
+
+	report := &Report{}
+	for _, p := range s.Patterns {
+		for _, example := range p.Match {
+			res := check(store, example.Input)
+			res.Pattern, res.WantMatch, res.Want = p.Name, true, example.Params
+			res.Passed = res.GotPattern == p.Name && (example.Params == nil || equalParams(example.Params, res.Got))
+			report.Results = append(report.Results, res)
+		}
+		for _, in := range p.NoMatch {
+			res := check(store, in)
+			res.Pattern = p.Name
+			res.Passed = res.GotPattern != p.Name
+			report.Results = append(report.Results, res)
+		}
+	}
+	for _, in := range s.Unmatched {
+		res := check(store, in)
+		res.Passed = !res.GotMatch
+		report.Results = append(report.Results, res)
+	}
+
+	return report, nil
+}
+
+func check(store *strparam.Store, in string) Result {
+	res := Result{Input: in}
+	found := store.Find(in)
+	if found == nil {
+		return res
+	}
+	matched, params := found.Lookup(in)
+	if !matched {
+		return res
+	}
+	res.GotMatch, res.GotPattern = true, found.Name()
+	res.Got = append(strparam.Params{}, params...)
+	return res
+}
+
+func equalParams(want map[string]string, got strparam.Params) bool {
+	if len(want) != len(got) {
+		return false
+	}
+	for _, param := range got {
+		if value, exists := want[param.Name]; !exists || value != param.Value {
+			return false
+		}
+	}
+	return true
+}
+
+// Result of checking the example.
+type Result struct {
+	// Pattern name of the pattern under test (empty for unmatched examples).
+	Pattern string
+	Input   string
+	// WantMatch is true if the example should be matched by the pattern with params Want.
+	WantMatch bool
+	Want      map[string]string
+
+	// GotPattern name of the pattern that won.
+	GotPattern string
+	GotMatch   bool
+	Got        strparam.Params
+
+	Passed bool
+}
+
+// Name returns short description of the example.
+func (r Result) Name() string {
+	switch {
+	case r.WantMatch:
+		return fmt.Sprintf("%s: match %q", r.Pattern, r.Input)
+	case r.Pattern != "":
+		return fmt.Sprintf("%s: nomatch %q", r.Pattern, r.Input)
+	}
+	return fmt.Sprintf("unmatched %q", r.Input)
+}
+
+// Diff returns the difference of expected and actual (empty if passed).
+func (r Result) Diff() string {
+	if r.Passed {
+		return ""
+	}
+
+	res := new(strings.Builder)
+	switch {
+	case r.WantMatch && r.GotPattern != r.Pattern:
+		fmt.Fprintf(res, "want pattern %q, got %s\n", r.Pattern, r.gotPatternString())
+	case r.WantMatch:
+		fmt.Fprintln(res, "params:")
+		got := map[string]string{}
+		for _, param := range r.Got {
+			got[param.Name] = param.Value
+		}
+		for _, name := range unionKeys(r.Want, got) {
+			want, wantExists := r.Want[name]
+			value, gotExists := got[name]
+			if wantExists && gotExists && want == value {
+				fmt.Fprintf(res, "    %s: %q\n", name, value)
+				continue
+			}
+			if wantExists {
+				fmt.Fprintf(res, "  - %s: %q\n", name, want)
+			}
+			if gotExists {
+				fmt.Fprintf(res, "  + %s: %q\n", name, value)
+			}
+		}
+	default:
+		fmt.Fprintf(res, "want no match, got %s\n", r.gotPatternString())
+	}
+	return res.String()
+}
+
+func (r Result) gotPatternString() string {
+	if !r.GotMatch {
+		return "no match"
+	}
+	return fmt.Sprintf("pattern %q with params %s", r.GotPattern, paramsString(r.Got))
+}
+
+func paramsString(params strparam.Params) string {
+	items := make([]string, 0, len(params))
+	for _, param := range params {
+		items = append(items, fmt.Sprintf("%s=%q", param.Name, param.Value))
+	}
+	return "{" + strings.Join(items, ", ") + "}"
+}
+
+func unionKeys(a, b map[string]string) []string {
+	keys := []string{}
+	for key := range a {
+		keys = append(keys, key)
+	}
+	for key := range b {
+		if _, exists := a[key]; !exists {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Report results of checking of spec.
+type Report struct {
+	Results []Result
+}
+
+// Failed returns failed results.
+func (r *Report) Failed() []Result {
+	var res []Result
+	for _, item := range r.Results {
+		if !item.Passed {
+			res = append(res, item)
+		}
+	}
+	return res
+}
+
+// String returns the failed results with differences and summary.
+func (r *Report) String() string {
+	res := new(strings.Builder)
+	failed := r.Failed()
+	for _, item := range failed {
+		fmt.Fprintf(res, "FAIL %s\n", item.Name())
+		for _, line := range strings.Split(strings.TrimSuffix(item.Diff(), "\n"), "\n") {
+			fmt.Fprintf(res, "    %s\n", line)
+		}
+	}
+	fmt.Fprintf(res, "%d examples, %d passed, %d failed\n", len(r.Results), len(r.Results)-len(failed), len(failed))
+	return res.String()
+}
+
+// RunSpec checks all examples of the spec file, an example per subtest.
+func RunSpec(t *testing.T, path string) {
+	t.Helper()
+
+	spec, err := LoadSpec(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	report, err := spec.Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, item := range report.Results {
+		item := item
+		t.Run(item.Name(), func(t *testing.T) {
+			if !item.Passed {
+				t.Error("\n" + item.Diff())
+			}
+		})
+	}
+}
@@ -0,0 +1,58 @@
+package strparamtest
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunSpec(t *testing.T) {
+	RunSpec(t, "testdata/login.yaml")
+}
+
+func TestSpec_Run(t *testing.T) {
+	spec, err := LoadSpec("testdata/failed.yaml")
+	require.NoError(t, err)
+	report, err := spec.Run()
+	require.NoError(t, err)
+
+	require.Len(t, report.Results, 4)
+	assert.Len(t, report.Failed(), 4)
+	assert.Equal(t, `FAIL login: match "user bob logged in"
+    params:
+      - name: "alice"
+      + name: "bob"
+FAIL login: match "user bob logged out"
+    want pattern "login", got no match
+FAIL login: nomatch "user alice logged in"
+    want no match, got pattern "login" with params {name="alice"}
+FAIL unmatched "user bob logged in"
+    want no match, got pattern "login" with params {name="bob"}
+4 examples, 0 passed, 4 failed
+`, report.String())
+}
+
+func TestParseSpec(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		wantErr string
+	}{
+		{"empty", ``, "no patterns"},
+		{"unknown field", "patterns:\n  - name: a\n    patern: a\n", "yaml: unmarshal errors:\n  line 3: field patern not found in type strparamtest.SpecPattern"},
+		{"empty name", "patterns:\n  - pattern: a\n", "pattern #1: empty name"},
+		{"duplicate name", "patterns:\n  - {name: a, pattern: a}\n  - {name: a, pattern: b}\n", `pattern #2: duplicate name "a"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseSpec([]byte(tt.data))
+			require.EqualError(t, err, tt.wantErr)
+		})
+	}
+
+	spec, err := ParseSpec([]byte("patterns:\n  - {name: a, pattern: '{'}\n"))
+	require.NoError(t, err)
+	_, err = spec.Run()
+	require.EqualError(t, err, `pattern "a": failed parse: parameter was not closed, pos 0`)
+}
@@ -3,6 +3,7 @@ package strparam
 import (
 	"bytes"
 	"fmt"
+	"strings"
 )
 
 var DefaultStartParam = '{'
@@ -15,11 +16,14 @@ type Token struct {
 	// multifunctional field
 	Raw   string
 	Param *Token
+	// Segment the value of parameter (for PARAMETER token) is a non-empty segment of path (without slash),
+	// see SegmentParameterToken.
+	Segment bool
 }
 
 // Equal returns true if mode and length and values is equal.
 func (t Token) Equal(in Token) bool {
-	return t.Mode == in.Mode && t.Len == in.Len && t.Raw == in.Raw
+	return t.Mode == in.Mode && t.Len == in.Len && t.Raw == in.Raw && t.Segment == in.Segment
 }
 
 // String returns human-readable format of token .
@@ -32,6 +36,9 @@ func (t *Token) String() string {
 	case SEPARATOR:
 		return fmt.Sprintf("Separator(%q, len=%d)", t.Raw, t.Len)
 	case PARAMETER:
+		if t.Segment {
+			return fmt.Sprintf("Param(%q, segment)", t.Raw)
+		}
 		return fmt.Sprintf("Param(%q)", t.Raw)
 	case PARAMETER_PARSED:
 		return fmt.Sprintf("ParsedParam(%s=%q)", t.ParamName(), t.Raw)
@@ -60,6 +67,12 @@ func (t *Token) ParamName() string {
 	return ""
 }
 
+// acceptsValue returns true if the value is allowed for the parameter (PARAMETER token):
+// any value or a non-empty segment of path for the segment parameter.
+func (t *Token) acceptsValue(value string) bool {
+	return !t.Segment || value != "" && strings.IndexByte(value, '/') < 0
+}
+
 // Tokens helper type for list tokens.
 type Tokens []Token
 
@@ -147,6 +160,14 @@ func ParameterToken(rawName string) Token {
 	}
 }
 
+// SegmentParameterToken returns a token of type PARAM, the value of parameter is a non-empty segment of path
+// (does not contain slash), eg :name of ColonDialect.
+func SegmentParameterToken(rawName string) Token {
+	t := ParameterToken(rawName)
+	t.Segment = true
+	return t
+}
+
 // ParsedParameterToken returns a token of type PARSED_PARAM.
 func ParsedParameterToken(rawName, val string) Token {
 	return Token{
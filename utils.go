@@ -17,9 +17,15 @@ func TokenSchemaString(t Token) string {
 	case SEPARATOR:
 		return fmt.Sprintf("Separator(%q, len=%d)", t.Raw, t.Len)
 	case PARAMETER:
+		if t.Segment {
+			return fmt.Sprintf("Param(%q, segment)", t.ParamName())
+		}
 		return fmt.Sprintf("Param(%q)", t.ParamName())
 	case PARAMETER_PARSED:
 		// this is primarily a parameter
+		if t.Param != nil && t.Param.Segment {
+			return fmt.Sprintf("Param(%q, segment)", t.ParamName())
+		}
 		return fmt.Sprintf("Param(%q)", t.ParamName())
 	case START:
 		return fmt.Sprintf("START")
@@ -0,0 +1,53 @@
+package strparam
+
+// Walk calls fn for each pattern of storage in order of the tree (see Store.FindAll),
+// stops if fn returns false.
+//
+// Patterns are the same as added to the storage (restored from the tree after UnmarshalBinary).
+func (r *Store) Walk(fn func(p *Pattern) bool) {
+	walkPatterns(r.load().root, fn)
+}
+
+// Patterns returns all patterns of storage in order of the tree.
+func (r *Store) Patterns() []*Pattern {
+	state := r.load()
+	res := make([]*Pattern, 0, state.numPatterns)
+	walkPatterns(state.root, func(p *Pattern) bool {
+		res = append(res, p)
+		return true
+	})
+	return res
+}
+
+// Get returns the pattern by name (see AddNamed).
+//
+// Returns false if the pattern with the name does not exist.
+func (r *Store) Get(name string) (*Pattern, bool) {
+	var res *Pattern
+	walkPatterns(r.load().root, func(p *Pattern) bool {
+		if p.Name() == name {
+			res = p
+		}
+		return res == nil
+	})
+	return res, res != nil
+}
+
+// Len returns the number of patterns of storage.
+func (r *Store) Len() int {
+	return r.load().numPatterns
+}
+
+// walkPatterns calls fn for patterns of END nodes of the branch in order of the tree.
+// Returns false if the walk is stopped by fn.
+func walkPatterns(n *node, fn func(p *Pattern) bool) bool {
+	if n.Token.Mode == END && n.pattern != nil && !fn(n.pattern) {
+		return false
+	}
+	for _, child := range n.Childs {
+		if !walkPatterns(child, fn) {
+			return false
+		}
+	}
+	return true
+}
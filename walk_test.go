@@ -0,0 +1,122 @@
+package strparam
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStore_Walk(t *testing.T) {
+	s := NewStore()
+	index, err := s.AddNamed("index", "/")
+	require.NoError(t, err)
+	users, err := s.AddNamed("users", "/users/{id}")
+	require.NoError(t, err)
+	files, err := s.AddNamed("files", "/users/{id}/files")
+	require.NoError(t, err)
+	unnamed, err := s.Add("/{path}")
+	require.NoError(t, err)
+
+	// in order of the tree
+	assert.Equal(t, []*Pattern{files, users, index, unnamed}, s.Patterns())
+	assert.Equal(t, 4, s.Len())
+
+	var visited []*Pattern
+	s.Walk(func(p *Pattern) bool {
+		visited = append(visited, p)
+		return len(visited) < 2
+	})
+	assert.Equal(t, []*Pattern{files, users}, visited)
+
+	got, found := s.Get("users")
+	assert.True(t, found)
+	assert.Same(t, users, got)
+	got, found = s.Get("notexists")
+	assert.False(t, found)
+	assert.Nil(t, got)
+
+	// the same pattern again does not change the number of patterns
+	s.Add("/{path}")
+	assert.Equal(t, 4, s.Len())
+
+	assert.True(t, s.RemoveNamed("users"))
+	_, found = s.Get("users")
+	assert.False(t, found)
+	assert.Equal(t, 3, s.Len())
+
+	_, err = s.Replace("files", "/files/{id}")
+	require.NoError(t, err)
+	got, found = s.Get("files")
+	assert.True(t, found)
+	assert.Equal(t, "/files/{id}", got.Source())
+	assert.Equal(t, 3, s.Len())
+
+	// patterns are restored from the tree after decoding from binary format
+	data, err := s.MarshalBinary()
+	require.NoError(t, err)
+	loaded := NewStore()
+	require.NoError(t, loaded.UnmarshalBinary(data))
+	assert.Equal(t, 3, loaded.Len())
+	got, found = loaded.Get("files")
+	assert.True(t, found)
+	assert.Equal(t, "/files/{id}", got.Source())
+	_, err = loaded.AddNamed("files", "/other")
+	require.EqualError(t, err, `pattern with name "files" already exists`)
+
+	empty := &Store{}
+	assert.Empty(t, empty.Patterns())
+	assert.Equal(t, 0, empty.Len())
+}
+
+func TestStore_AddNamed_Duplicate(t *testing.T) {
+	s := NewStore()
+	_, err := s.AddNamed("users", "/users/{id}")
+	require.NoError(t, err)
+	before := s.String()
+
+	_, err = s.AddNamed("users", "/users/{id}")
+	require.EqualError(t, err, `pattern with name "users" already exists`)
+	_, err = s.AddNamed("users", "/other/{id}")
+	require.EqualError(t, err, `pattern with name "users" already exists`)
+	assert.Equal(t, before, s.String())
+	assert.Equal(t, 1, s.Len())
+
+	// unnamed patterns are not checked
+	_, err = s.Add("/foo")
+	require.NoError(t, err)
+	_, err = s.Add("/foo")
+	require.NoError(t, err)
+
+	// the name is free after removal
+	assert.True(t, s.RemoveNamed("users"))
+	_, err = s.AddNamed("users", "/other/{id}")
+	require.NoError(t, err)
+	assert.Equal(t, 2, s.Len())
+
+	// other pattern with the same name is not added by AddPattern
+	other, err := ParseWithName("users", "/another/{id}")
+	require.NoError(t, err)
+	require.EqualError(t, s.AddPatternWithValue(other, 1), `pattern with name "users" already exists`)
+	assert.Nil(t, s.Find("/another/1"))
+	assert.Equal(t, 2, s.Len())
+	got, found := s.Get("users")
+	assert.True(t, found)
+	assert.Equal(t, "/other/{id}", got.Source())
+
+	// the same pattern again replaces the value
+	same, err := ParseWithName("users", "/other/{id}")
+	require.NoError(t, err)
+	require.NoError(t, s.AddPatternWithValue(same, 2))
+	match, ok := s.Match("/other/1")
+	require.True(t, ok)
+	assert.Equal(t, 2, match.Value)
+	assert.Equal(t, 2, s.Len())
+
+	// the empty name is not a name of unnamed patterns
+	assert.False(t, s.RemoveNamed(""))
+	_, err = s.Replace("", "/bar")
+	require.EqualError(t, err, "name of pattern is empty")
+	assert.NotNil(t, s.Find("/foo"))
+	assert.Equal(t, 2, s.Len())
+}